@@ -0,0 +1,299 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/core"
+	"github.com/dylenfu/zion-tool/flag"
+	"github.com/dylenfu/zion-tool/pkg/files"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/dylenfu/zion-tool/pkg/server"
+	"github.com/urfave/cli"
+)
+
+// selectFlags choose the steps of `run`
+var selectFlags = []cli.Flag{
+	flag.MethodsFlag,
+	flag.ScenarioFlag,
+	flag.RerunFailedFlag,
+}
+
+// deprecatedFlags are the flags of commands before subcommands
+var deprecatedFlags = []cli.Flag{
+	flag.ListFlag,
+	flag.DescribeFlag,
+	flag.GenCasesFlag,
+	flag.ShellFlag,
+	flag.ServeFlag,
+	flag.TokenFlag,
+}
+
+// paramsFunc builds method params from command flags
+type paramsFunc func(ctx *cli.Context) (interface{}, error)
+
+func commands() []cli.Command {
+	return []cli.Command{
+		{
+			Name:   "run",
+			Usage:  "run methods selected by -t, a scenario, or the failed methods of the last run",
+			Flags:  joinFlags(commonFlags, runFlags, selectFlags),
+			Action: runMethods,
+		},
+		{
+			Name:   "methods",
+			Usage:  "list registered methods",
+			Flags:  commonFlags,
+			Action: listMethods,
+		},
+		{
+			Name:      "describe",
+			Usage:     "describe the method and its params",
+			ArgsUsage: "<method>",
+			Flags:     commonFlags,
+			Action:    describeMethod,
+		},
+		{
+			Name:   "gen-cases",
+			Usage:  "write sample case file of every method into workspace cases dir",
+			Flags:  commonFlags,
+			Action: genCases,
+		},
+		{
+			Name:   "shell",
+			Usage:  "interactive shell for registered methods and chain operations",
+			Flags:  joinFlags(commonFlags, runFlags),
+			Action: runShell,
+		},
+		{
+			Name:   "serve",
+			Usage:  "run as http control server",
+			Flags:  joinFlags(commonFlags, runFlags, []cli.Flag{flag.AddrFlag, flag.TokenFlag}),
+			Action: serve,
+		},
+		methodCommand("transfer", []cli.Flag{flag.ToFlag, flag.AmountFlag}, transferParams),
+		methodCommand("header", []cli.Flag{flag.HeightFlag}, headerParams),
+		methodCommand("register", []cli.Flag{flag.NodesFlag, flag.AmountFlag}, registerParams),
+		methodCommand("stake", []cli.Flag{flag.NodesFlag, flag.AmountFlag}, stakeParams),
+		methodCommand("epoch", nil, nil),
+		methodCommand("bench", []cli.Flag{flag.TxPerPeriod}, benchParams),
+	}
+}
+
+// methodCommand runs the registered method with params built from the flags,
+// the method loads its case file as before if none of the flags is set.
+func methodCommand(name string, paramFlags []cli.Flag, params paramsFunc) cli.Command {
+	usage := name + " method"
+	if info, err := frame.Tool.Describe(name); err == nil && info.Description != "" {
+		usage = info.Description
+	}
+	return cli.Command{
+		Name:  name,
+		Usage: usage,
+		Flags: joinFlags(commonFlags, runFlags, paramFlags),
+		Action: func(ctx *cli.Context) error {
+			if err := setup(ctx); err != nil {
+				return err
+			}
+			step, err := methodStep(ctx, name, paramFlags, params)
+			if err != nil {
+				log.Error(err)
+				return exit(exitSetup)
+			}
+			return runSteps(ctx, []*frame.Step{step})
+		},
+	}
+}
+
+// methodStep builds the step of method command, params are left empty if
+// none of the param flags is set so that the method loads its case file.
+func methodStep(ctx *cli.Context, name string, paramFlags []cli.Flag, params paramsFunc) (*frame.Step, error) {
+	step := &frame.Step{Name: name, Method: name}
+	if params == nil || !flag.IsSet(ctx, paramFlags...) {
+		return step, nil
+	}
+	param, err := params(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid params of %s, err: %v", name, err)
+	}
+	if step.Params, err = json.Marshal(param); err != nil {
+		return nil, fmt.Errorf("failed to marshal params of %s, err: %v", name, err)
+	}
+	return step, nil
+}
+
+func transferParams(ctx *cli.Context) (interface{}, error) {
+	return &core.TransferParam{
+		To:     flag.Flag2list(ctx, flag.ToFlag),
+		Amount: flag.Flag2Uint64(ctx, flag.AmountFlag),
+	}, nil
+}
+
+func headerParams(ctx *cli.Context) (interface{}, error) {
+	return &core.HeaderParam{Height: flag.Flag2Uint64(ctx, flag.HeightFlag)}, nil
+}
+
+func registerParams(ctx *cli.Context) (interface{}, error) {
+	nodes, err := flag.Flag2intList(ctx, flag.NodesFlag)
+	if err != nil {
+		return nil, err
+	}
+	return &core.RegisterParam{
+		NodeIndexList: nodes,
+		StakeAmount:   int(flag.Flag2Uint64(ctx, flag.AmountFlag)),
+	}, nil
+}
+
+func stakeParams(ctx *cli.Context) (interface{}, error) {
+	nodes, err := flag.Flag2intList(ctx, flag.NodesFlag)
+	if err != nil {
+		return nil, err
+	}
+	return &core.StakeParam{
+		NodeIndexList: nodes,
+		StakeAmount:   int(flag.Flag2Uint64(ctx, flag.AmountFlag)),
+	}, nil
+}
+
+func benchParams(ctx *cli.Context) (interface{}, error) {
+	return &core.BenchParam{TxNum: flag.Flag2Uint64(ctx, flag.TxPerPeriod)}, nil
+}
+
+// rootAction runs methods without subcommand, or dispatches the deprecated
+// flags to their commands.
+func rootAction(ctx *cli.Context) error {
+	switch {
+	case flag.Flag2bool(ctx, flag.ListFlag):
+		log.Warn("flag -list is deprecated, use command methods instead")
+		return listMethods(ctx)
+	case flag.Flag2string(ctx, flag.DescribeFlag) != "":
+		log.Warn("flag -describe is deprecated, use command describe instead")
+		return printMethod(ctx, flag.Flag2string(ctx, flag.DescribeFlag))
+	case flag.Flag2bool(ctx, flag.GenCasesFlag):
+		log.Warn("flag -gen-cases is deprecated, use command gen-cases instead")
+		return genCases(ctx)
+	case flag.Flag2bool(ctx, flag.ShellFlag):
+		log.Warn("flag -shell is deprecated, use command shell instead")
+		return runShell(ctx)
+	case flag.Flag2string(ctx, flag.ServeFlag) != "":
+		log.Warn("flag -serve is deprecated, use command serve instead")
+		return serveAt(ctx, flag.Flag2string(ctx, flag.ServeFlag))
+	}
+	return runMethods(ctx)
+}
+
+func runMethods(ctx *cli.Context) error {
+	if err := setup(ctx); err != nil {
+		return err
+	}
+
+	var steps []*frame.Step
+	if flag.Flag2bool(ctx, flag.RerunFailedFlag) {
+		record, err := frame.LoadRunRecord(recordPath())
+		if err != nil {
+			log.Errorf("failed to load last run record, err: %v", err)
+			return exit(exitSetup)
+		}
+		steps = record.FailedSteps()
+		if len(steps) == 0 {
+			log.Infof("no failed method in the last run %s", record.RunID)
+			return nil
+		}
+		log.Infof("rerun %d failed methods of the last run %s", len(steps), record.RunID)
+	} else if scenario := flag.Flag2string(ctx, flag.ScenarioFlag); scenario != "" {
+		sc, err := config.LoadScenario(scenario)
+		if err != nil {
+			log.Errorf("failed to load scenario, err: %v", err)
+			return exit(exitSetup)
+		}
+		log.Infof("run scenario %s", sc.Name)
+		steps = sc.Steps
+	} else {
+		methods := make([]string, 0)
+		if selectors := flag.Flag2list(ctx, flag.MethodsFlag); len(selectors) > 0 {
+			selected, err := frame.Tool.Select(selectors)
+			if err != nil {
+				log.Errorf("failed to select methods, err: %v", err)
+				return exit(exitSetup)
+			}
+			methods = selected
+		}
+		steps = frame.NewSteps(methods)
+	}
+
+	return runSteps(ctx, steps)
+}
+
+func listMethods(ctx *cli.Context) error {
+	if err := loadWorkspace(ctx); err != nil {
+		return err
+	}
+	frame.Tool.PrintMethods()
+	return nil
+}
+
+func describeMethod(ctx *cli.Context) error {
+	return printMethod(ctx, ctx.Args().First())
+}
+
+func printMethod(ctx *cli.Context, name string) error {
+	if err := loadWorkspace(ctx); err != nil {
+		return err
+	}
+	if err := frame.Tool.PrintMethod(name); err != nil {
+		log.Error(err)
+		return exit(exitSetup)
+	}
+	return nil
+}
+
+func genCases(ctx *cli.Context) error {
+	if err := loadWorkspace(ctx); err != nil {
+		return err
+	}
+	written, err := frame.Tool.GenerateCases(files.FullPath(config.Conf.Workspace, "cases", ""))
+	for _, file := range written {
+		log.Infof("generate case file %s", file)
+	}
+	if err != nil {
+		log.Errorf("failed to generate case files, err: %v", err)
+		return exit(exitSetup)
+	}
+	return nil
+}
+
+func runShell(ctx *cli.Context) error {
+	if err := setup(ctx); err != nil {
+		return err
+	}
+	if err := core.NewShell().Run(); err != nil {
+		log.Errorf("shell exit, err: %v", err)
+		return exit(exitSetup)
+	}
+	return nil
+}
+
+func serve(ctx *cli.Context) error {
+	return serveAt(ctx, flag.Flag2string(ctx, flag.AddrFlag))
+}
+
+func serveAt(ctx *cli.Context, addr string) error {
+	if err := setup(ctx); err != nil {
+		return err
+	}
+	srv := server.New(frame.Tool, func(name string) ([]*frame.Step, error) {
+		sc, err := config.LoadScenario(files.FullPath(config.Conf.Workspace, "scenarios", name))
+		if err != nil {
+			return nil, err
+		}
+		return sc.Steps, nil
+	})
+	srv.SetToken(flag.Flag2string(ctx, flag.TokenFlag))
+	if err := srv.ListenAndServe(addr); err != nil {
+		log.Errorf("control server exit, err: %v", err)
+		return exit(exitSetup)
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	goflag "flag"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dylenfu/zion-tool/flag"
+	"github.com/urfave/cli"
+)
+
+func TestMethodStep(t *testing.T) {
+	var testdata = []struct {
+		name   string
+		flags  []cli.Flag
+		params paramsFunc
+		args   []string
+		expect string
+		err    bool
+	}{
+		{"transfer", []cli.Flag{flag.ToFlag, flag.AmountFlag}, transferParams,
+			[]string{"--to=0x01, 0x02", "--amount=3"}, `{"To":["0x01","0x02"],"Amount":3}`, false},
+		{"transfer", []cli.Flag{flag.ToFlag, flag.AmountFlag}, transferParams,
+			[]string{"--amount=3"}, `{"To":[],"Amount":3}`, false},
+		{"header", []cli.Flag{flag.HeightFlag}, headerParams,
+			[]string{"--height=10"}, `{"Height":10}`, false},
+		{"register", []cli.Flag{flag.NodesFlag, flag.AmountFlag}, registerParams,
+			[]string{"--nodes=1,2", "--amount=5"}, `{"NodeIndexList":[1,2],"StakeAmount":5}`, false},
+		{"stake", []cli.Flag{flag.NodesFlag, flag.AmountFlag}, stakeParams,
+			[]string{"--nodes=1,x"}, "", true},
+		{"bench", []cli.Flag{flag.TxPerPeriod}, benchParams,
+			[]string{"--txn=5"}, `{"TxNum":5,"Amount":0}`, false},
+		// methods load their case files if no param flag set
+		{"transfer", []cli.Flag{flag.ToFlag, flag.AmountFlag}, transferParams, nil, "", false},
+		{"bench", []cli.Flag{flag.TxPerPeriod}, benchParams, []string{"--num=2"}, "", false},
+		{"epoch", nil, nil, nil, "", false},
+	}
+	for _, v := range testdata {
+		set := goflag.NewFlagSet(v.name, goflag.ContinueOnError)
+		for _, f := range joinFlags(runFlags, v.flags) {
+			f.Apply(set)
+		}
+		if err := set.Parse(v.args); err != nil {
+			t.Fatal(err)
+		}
+
+		step, err := methodStep(cli.NewContext(nil, set, nil), v.name, v.flags, v.params)
+		if (err != nil) != v.err {
+			t.Errorf("%s %v expect error %v, got %v", v.name, v.args, v.err, err)
+			continue
+		}
+		if v.err {
+			continue
+		}
+		if step.Name != v.name || step.Method != v.name || string(step.Params) != v.expect {
+			t.Errorf("%s %v expect params %s, got %+v %s", v.name, v.args, v.expect, step, step.Params)
+		}
+	}
+}
+
+func TestDeprecatedFlags(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "scripts"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	script := "function main(params) { return true }"
+	if err := ioutil.WriteFile(filepath.Join(dir, "scripts", "deprecated_script.js"), []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+	configPath := filepath.Join(dir, "config.json")
+	if err := ioutil.WriteFile(configPath, []byte(`{"Workspace":"`+dir+`"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	// app exits with the code of failed command
+	exiter := cli.OsExiter
+	defer func() { cli.OsExiter = exiter }()
+	cli.OsExiter = func(int) {}
+
+	app := cli.NewApp()
+	app.Commands = commands()
+	app.Flags = joinFlags(commonFlags, runFlags, selectFlags, deprecatedFlags)
+	app.Action = rootAction
+
+	config := "-config=" + configPath
+	var testdata = []struct {
+		args []string
+		code int
+	}{
+		{[]string{config, "-list"}, exitOK},
+		{[]string{"methods", config}, exitOK},
+		// scripts are loaded before describing
+		{[]string{config, "-describe=deprecated_script"}, exitOK},
+		{[]string{"describe", config, "deprecated_script"}, exitOK},
+		{[]string{config, "-describe=unknown"}, exitSetup},
+	}
+	for _, v := range testdata {
+		code := exitOK
+		if err := app.Run(append([]string{"zion-tool"}, v.args...)); err != nil {
+			code = exitSetup
+			if coder, ok := err.(cli.ExitCoder); ok {
+				code = coder.ExitCode()
+			}
+		}
+		if code != v.code {
+			t.Errorf("args %v expect exit %d, got %d", v.args, v.code, code)
+		}
+	}
+}
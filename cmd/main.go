@@ -1,43 +1,200 @@
 package main
 
 import (
-	"flag"
+	"math/big"
 	"math/rand"
-	"strings"
+	"os"
+	"path"
 	"time"
 
 	"github.com/dylenfu/zion-tool/config"
 	"github.com/dylenfu/zion-tool/core"
+	"github.com/dylenfu/zion-tool/flag"
+	"github.com/dylenfu/zion-tool/pkg/files"
 	"github.com/dylenfu/zion-tool/pkg/frame"
 	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/dylenfu/zion-tool/pkg/sdk"
+	"github.com/urfave/cli"
 )
 
-var (
-	loglevel   int    // log level [1: debug, 2: info]
-	configpath string //config file
-	Methods    string //Methods list in cmdline
+// lastRunFile is the run record persisted in workspace
+const lastRunFile = "last_run.json"
+
+const (
+	exitOK     = 0
+	exitFailed = 1 // some methods failed or skipped
+	exitSetup  = 2 // invalid config, scenario or params
 )
 
-func init() {
-	flag.StringVar(&configpath, "config", "config.json", "configpath of palette-tool")
-	flag.StringVar(&Methods, "t", "demo", "methods to run. use ',' to split methods")
-	flag.IntVar(&loglevel, "loglevel", 2, "loglevel [1: debug, 2: info]")
+var (
+	// commonFlags are accepted by every command
+	commonFlags = []cli.Flag{
+		flag.ConfigPathFlag,
+		flag.LogLevelFlag,
+		flag.CIFlag,
+	}
 
-	flag.Parse()
-}
+	// runFlags control how methods are scheduled and reported
+	runFlags = []cli.Flag{
+		flag.StateFlag,
+		flag.WorkersFlag,
+		flag.IntervalFlag,
+		flag.ReportFlag,
+		flag.JUnitFlag,
+		flag.FailFastFlag,
+		flag.NumberFlag,
+		flag.PeriodFlag,
+		flag.IncrGasPrice,
+	}
+)
 
 func main() {
 	rand.Seed(time.Now().UnixNano())
-	defer time.Sleep(time.Second)
 
-	log.InitLog(loglevel, log.Stdout)
-	config.LoadConfig(configpath)
+	// methods are registered before building commands, which show their descriptions
 	core.Endpoint()
 
-	methods := make([]string, 0)
-	if Methods != "" {
-		methods = strings.Split(Methods, ",")
+	app := cli.NewApp()
+	app.Name = "zion-tool"
+	app.Usage = "zion test tool"
+	app.HideVersion = true
+	app.Commands = commands()
+
+	// methods run without subcommand as before, e.g: zion-tool -config=config.json -t=transfer
+	app.Flags = joinFlags(commonFlags, runFlags, selectFlags, deprecatedFlags)
+	app.Action = rootAction
+
+	if err := app.Run(os.Args); err != nil {
+		if coder, ok := err.(cli.ExitCoder); ok {
+			os.Exit(coder.ExitCode())
+		}
+		log.Error(err)
+		os.Exit(exitSetup)
+	}
+}
+
+func joinFlags(list ...[]cli.Flag) []cli.Flag {
+	flags := make([]cli.Flag, 0)
+	for _, item := range list {
+		flags = append(flags, item...)
+	}
+	return flags
+}
+
+// initLog initializes logger with the common flags
+func initLog(ctx *cli.Context) {
+	ci := flag.Flag2bool(ctx, flag.CIFlag)
+	log.SetColor(!ci && log.IsTerminal(os.Stdout))
+	log.InitLog(flag.Flag2int(ctx, flag.LogLevelFlag), log.Stdout)
+}
+
+// loadWorkspace loads config and the scripts registered as methods
+func loadWorkspace(ctx *cli.Context) error {
+	initLog(ctx)
+	config.LoadConfig(flag.Flag2string(ctx, flag.ConfigPathFlag))
+	if err := core.LoadScripts(); err != nil {
+		log.Errorf("failed to load scripts, err: %v", err)
+		return exit(exitSetup)
+	}
+	return nil
+}
+
+// setup loads workspace, and applies run flags to the tool
+func setup(ctx *cli.Context) error {
+	if err := loadWorkspace(ctx); err != nil {
+		return err
+	}
+
+	frame.Tool.SetWorkers(flag.Flag2int(ctx, flag.WorkersFlag))
+	frame.Tool.SetInterval(flag.Flag2time(ctx, flag.IntervalFlag))
+	frame.Tool.SetFailFast(flag.Flag2bool(ctx, flag.FailFastFlag))
+	frame.Tool.SetInstances(int(flag.Flag2Uint64(ctx, flag.NumberFlag)))
+	frame.Tool.SetTimeout(time.Duration(config.Conf.MethodTimeout))
+	for name, timeout := range config.Conf.MethodTimeouts {
+		frame.Tool.SetMethodTimeout(name, time.Duration(timeout))
+	}
+	frame.Tool.SetRecordPath(recordPath())
+	if hook := config.Conf.Webhook; hook != nil && hook.Url != "" {
+		frame.Tool.AddListener(newWebhookListener(hook))
+	}
+	if inc := flag.Flag2Uint64(ctx, flag.IncrGasPrice); inc > 0 {
+		sdk.SetDefaultGasStrategy(sdk.IncreaseGasPrice(sdk.DefaultGasStrategy(), new(big.Int).SetUint64(inc)))
+	}
+
+	if statePath := flag.Flag2string(ctx, flag.StateFlag); statePath != "" {
+		if !path.IsAbs(statePath) {
+			statePath = files.FullPath(config.Conf.Workspace, "", statePath)
+		}
+		if err := frame.Tool.SetStatePath(statePath); err != nil {
+			log.Errorf("failed to load run state, err: %v", err)
+			return exit(exitSetup)
+		}
+	}
+	return nil
+}
+
+func recordPath() string {
+	return files.FullPath(config.Conf.Workspace, "", lastRunFile)
+}
+
+// runSteps runs steps once, or repeats them in soak period, and then writes
+// the reports. it fails if any method failed or skipped.
+func runSteps(ctx *cli.Context, steps []*frame.Step) error {
+	if period := flag.Flag2string(ctx, flag.PeriodFlag); period != "" {
+		d, err := flag.ParsePeriod(period)
+		if err != nil {
+			log.Errorf("failed to parse soak period, err: %v", err)
+			return exit(exitSetup)
+		}
+		frame.Tool.Soak(steps, d)
+	} else {
+		frame.Tool.StartSteps(steps)
+	}
+
+	writeReports(ctx)
+
+	if report := frame.Tool.Report(); report != nil && (report.Failed > 0 || report.Skipped > 0) {
+		return exit(exitFailed)
 	}
+	return nil
+}
 
-	frame.Tool.Start(methods)
+// exit returns error with the non-zero code which the tool exits with
+func exit(code int) error {
+	if code == exitOK {
+		return nil
+	}
+	return cli.NewExitError("", code)
+}
+
+func writeReports(ctx *cli.Context) {
+	report := frame.Tool.Report()
+	if report == nil {
+		return
+	}
+	if jsonReport := flag.Flag2string(ctx, flag.ReportFlag); jsonReport != "" {
+		if err := report.WriteJSON(jsonReport); err != nil {
+			log.Errorf("failed to write json report, err: %v", err)
+		}
+	}
+	if junitReport := flag.Flag2string(ctx, flag.JUnitFlag); junitReport != "" {
+		if err := report.WriteJUnit(junitReport); err != nil {
+			log.Errorf("failed to write junit report, err: %v", err)
+		}
+	}
+}
+
+func newWebhookListener(hook *config.Webhook) *frame.WebhookListener {
+	events := make([]frame.EventType, 0, len(hook.Events))
+	for _, event := range hook.Events {
+		events = append(events, frame.EventType(event))
+	}
+	wl := frame.NewWebhookListener(hook.Url, events...)
+	for k, v := range hook.Headers {
+		wl.SetHeader(k, v)
+	}
+	if hook.Timeout > 0 {
+		wl.SetTimeout(time.Duration(hook.Timeout))
+	}
+	return wl
 }
@@ -25,6 +25,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/dylenfu/zion-tool/pkg/encode"
 	"github.com/dylenfu/zion-tool/pkg/files"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -41,12 +42,42 @@ type Config struct {
 	Nodes       []*Node
 	BlockPeriod int
 	InitBalance int
+
+	// MethodTimeout is the default timeout of every method, e.g: "5m", and
+	// MethodTimeouts overrides it for the named methods.
+	MethodTimeout  encode.Duration
+	MethodTimeouts map[string]encode.Duration
+
+	// WaitTimeout is the max time waiting for one block, e.g: "30s"
+	WaitTimeout encode.Duration
+
+	// Webhook receives json run events, e.g: chat-ops bot announcing results
+	Webhook *Webhook `json:",omitempty"`
+}
+
+type Webhook struct {
+	Url string
+	// Events filters the posted events, e.g: ["run_finish"], all events are
+	// posted if it's empty.
+	Events  []string
+	Headers map[string]string
+	Timeout encode.Duration
 }
 
 func (c *Config) BlockWaitingTime() time.Duration {
 	return time.Second * time.Duration(c.BlockPeriod+1)
 }
 
+// BlockWaitTimeout returns the timeout of waiting n blocks, it's WaitTimeout
+// per block if configured, and 10 times of block period otherwise.
+func (c *Config) BlockWaitTimeout(n uint64) time.Duration {
+	perBlock := time.Duration(c.WaitTimeout)
+	if perBlock <= 0 {
+		perBlock = 10 * c.BlockWaitingTime()
+	}
+	return perBlock * time.Duration(n)
+}
+
 type Node struct {
 	NodeKey         string            `json:"NodeKey"`
 	Url             string            `json:"Url"`
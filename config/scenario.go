@@ -0,0 +1,110 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/dylenfu/zion-tool/pkg/files"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"gopkg.in/yaml.v3"
+)
+
+// Scenario is an ordered list of steps, each step names a registered method
+// and carries its params inline. e.g:
+//
+//	{
+//	    "Name": "register node2",
+//	    "Vars": {"amount": 100},
+//	    "Steps": [
+//	        {"Method": "transfer", "Params": {"To": ["${nodes[2].StakeAddr}"], "Amount": "${vars.amount}"}},
+//	        {"Name": "register-2", "Method": "register", "Params": {"NodeIndexList": [2], "StakeAmount": 10000}}
+//	    ]
+//	}
+type Scenario struct {
+	Name  string
+	Vars  map[string]interface{}
+	Steps []*frame.Step
+}
+
+// LoadScenario reads scenario from json or yaml file, relative path which
+// does not exist is looked up in `Workspace/scenarios`.
+func LoadScenario(path string) (*Scenario, error) {
+	if _, err := os.Stat(path); os.IsNotExist(err) && !filepath.IsAbs(path) {
+		path = files.FullPath(Conf.Workspace, "scenarios", path)
+	}
+
+	data, err := files.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	if ext == ".yaml" || ext == ".yml" {
+		if data, err = yamlToJson(data); err != nil {
+			return nil, fmt.Errorf("invalid yaml scenario %s, err: %v", path, err)
+		}
+	}
+
+	scenario := new(Scenario)
+	if err := json.Unmarshal(data, scenario); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s, err: %v", path, err)
+	}
+	if err := scenario.expand(); err != nil {
+		return nil, fmt.Errorf("invalid scenario %s, err: %v", path, err)
+	}
+	return scenario, nil
+}
+
+// Scope returns variables which can be referenced in scenario params
+func (s *Scenario) Scope() map[string]interface{} {
+	return map[string]interface{}{
+		"vars":      s.Vars,
+		"nodes":     Conf.Nodes,
+		"chainID":   Conf.ChainID,
+		"workspace": Conf.Workspace,
+	}
+}
+
+func (s *Scenario) expand() error {
+	scope := s.Scope()
+	for i, step := range s.Steps {
+		if step.Method == "" {
+			return fmt.Errorf("step %d method is empty", i+1)
+		}
+		params, err := ExpandVars(step.Params, scope)
+		if err != nil {
+			return fmt.Errorf("step %d %s: %v", i+1, step.Method, err)
+		}
+		step.Params = params
+	}
+	return nil
+}
+
+func yamlToJson(data []byte) ([]byte, error) {
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
@@ -0,0 +1,202 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	varPattern  = regexp.MustCompile(`\$\{([^}]+)\}`)
+	pathPattern = regexp.MustCompile(`^(?:\.?([A-Za-z_][A-Za-z0-9_]*)|\[(\d+)\])`)
+)
+
+// ExpandVars replaces `${expr}` in every string value of the json document
+// with the value found by expr in scope, e.g: `${nodes[2].StakeAddr}`. a string
+// which only contains one variable is replaced by the json value itself, so
+// that numbers and lists keep their types.
+func ExpandVars(raw json.RawMessage, scope map[string]interface{}) (json.RawMessage, error) {
+	if len(raw) == 0 {
+		return raw, nil
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	expanded, err := expandValue(doc, scope)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(expanded)
+}
+
+func expandValue(v interface{}, scope map[string]interface{}) (interface{}, error) {
+	switch val := v.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			expanded, err := expandValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = expanded
+		}
+		return val, nil
+	case []interface{}:
+		for i, item := range val {
+			expanded, err := expandValue(item, scope)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = expanded
+		}
+		return val, nil
+	case string:
+		return expandString(val, scope)
+	default:
+		return v, nil
+	}
+}
+
+func expandString(s string, scope map[string]interface{}) (interface{}, error) {
+	matches := varPattern.FindAllStringSubmatchIndex(s, -1)
+	if len(matches) == 0 {
+		return s, nil
+	}
+
+	// the whole string is a single variable
+	if len(matches) == 1 && matches[0][0] == 0 && matches[0][1] == len(s) {
+		return resolveVar(s[matches[0][2]:matches[0][3]], scope)
+	}
+
+	var (
+		sb   strings.Builder
+		last int
+	)
+	for _, m := range matches {
+		value, err := resolveVar(s[m[2]:m[3]], scope)
+		if err != nil {
+			return nil, err
+		}
+		sb.WriteString(s[last:m[0]])
+		if str, ok := value.(string); ok {
+			sb.WriteString(str)
+		} else {
+			enc, _ := json.Marshal(value)
+			sb.Write(enc)
+		}
+		last = m[1]
+	}
+	sb.WriteString(s[last:])
+	return sb.String(), nil
+}
+
+// resolveVar looks up expr in scope and converts the value to its json form
+func resolveVar(expr string, scope map[string]interface{}) (interface{}, error) {
+	value, err := LookupPath(scope, strings.TrimSpace(expr))
+	if err != nil {
+		return nil, fmt.Errorf("variable ${%s}: %v", expr, err)
+	}
+	enc, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("variable ${%s}: %v", expr, err)
+	}
+	var v interface{}
+	if err := json.Unmarshal(enc, &v); err != nil {
+		return nil, fmt.Errorf("variable ${%s}: %v", expr, err)
+	}
+	return v, nil
+}
+
+// LookupPath walks through maps, struct fields and slices by path like
+// `nodes[2].StakeAddr`, names are matched case-insensitively.
+func LookupPath(root interface{}, path string) (interface{}, error) {
+	cur := reflect.ValueOf(root)
+	rest := path
+	for rest != "" {
+		m := pathPattern.FindStringSubmatch(rest)
+		if m == nil {
+			return nil, fmt.Errorf("invalid path %s", path)
+		}
+		rest = rest[len(m[0]):]
+
+		for cur.IsValid() && (cur.Kind() == reflect.Ptr || cur.Kind() == reflect.Interface) {
+			cur = cur.Elem()
+		}
+		if !cur.IsValid() {
+			return nil, fmt.Errorf("nil value before %s", m[0])
+		}
+
+		if m[1] != "" {
+			next, err := lookupName(cur, m[1])
+			if err != nil {
+				return nil, err
+			}
+			cur = next
+			continue
+		}
+
+		index, _ := strconv.Atoi(m[2])
+		if cur.Kind() != reflect.Slice && cur.Kind() != reflect.Array {
+			return nil, fmt.Errorf("can not index %s with [%d]", cur.Type(), index)
+		}
+		if index >= cur.Len() {
+			return nil, fmt.Errorf("index [%d] out of range %d", index, cur.Len())
+		}
+		cur = cur.Index(index)
+	}
+
+	if !cur.IsValid() {
+		return nil, nil
+	}
+	return cur.Interface(), nil
+}
+
+func lookupName(v reflect.Value, name string) (reflect.Value, error) {
+	switch v.Kind() {
+	case reflect.Map:
+		if v.Type().Key().Kind() != reflect.String {
+			break
+		}
+		if value := v.MapIndex(reflect.ValueOf(name).Convert(v.Type().Key())); value.IsValid() {
+			return value, nil
+		}
+		for _, key := range v.MapKeys() {
+			if strings.EqualFold(key.String(), name) {
+				return v.MapIndex(key), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("key %s not found", name)
+	case reflect.Struct:
+		typ := v.Type()
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if field.PkgPath == "" && strings.EqualFold(field.Name, name) {
+				return v.Field(i), nil
+			}
+		}
+		return reflect.Value{}, fmt.Errorf("field %s not found in %s", name, typ)
+	}
+	return reflect.Value{}, fmt.Errorf("can not lookup %s in %s", name, v.Type())
+}
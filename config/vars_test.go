@@ -0,0 +1,61 @@
+package config
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestExpandVars(t *testing.T) {
+	type node struct {
+		Url       string
+		StakeAddr string
+	}
+	scope := map[string]interface{}{
+		"nodes": []*node{
+			{Url: "http://127.0.0.1:22000", StakeAddr: "0x01"},
+			{Url: "http://127.0.0.1:22001", StakeAddr: "0x02"},
+		},
+		"vars": map[string]interface{}{
+			"amount": 10,
+			"list":   []int{1, 2},
+		},
+	}
+
+	var testdata = []struct {
+		input  string
+		expect string
+	}{
+		{
+			input:  `{"To":["${nodes[1].StakeAddr}"],"Amount":"${vars.amount}"}`,
+			expect: `{"Amount":10,"To":["0x02"]}`,
+		},
+		{
+			input:  `{"Url":"node ${nodes[0].url} amount ${vars.amount}"}`,
+			expect: `{"Url":"node http://127.0.0.1:22000 amount 10"}`,
+		},
+		{
+			input:  `{"NodeIndexList":"${vars.list}","Height":1}`,
+			expect: `{"Height":1,"NodeIndexList":[1,2]}`,
+		},
+	}
+
+	for _, v := range testdata {
+		output, err := ExpandVars(json.RawMessage(v.input), scope)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(output) != v.expect {
+			t.Errorf("expect %s, got %s", v.expect, output)
+		}
+	}
+
+	for _, input := range []string{
+		`{"To":"${nodes[2].StakeAddr}"}`,
+		`{"To":"${nodes[0].Unknown}"}`,
+		`{"To":"${nodes.x}"}`,
+	} {
+		if _, err := ExpandVars(json.RawMessage(input), scope); err == nil {
+			t.Errorf("expect error for %s", input)
+		}
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"time"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/ethereum/go-ethereum/common"
+)
+
+const caseBench = "test_bench.json"
+
+type BenchParam struct {
+	TxNum  uint64 `desc:"number of transfers sent by each instance"`
+	Amount uint64 `desc:"amount of each transfer in wei, default to 1"`
+}
+
+// Bench sends TxNum transfers to a random address without waiting for each
+// of them, and measures the tps until all of them are packed.
+func Bench(ctx context.Context) *frame.Result {
+	var param BenchParam
+
+	if err := loadParams(ctx, caseBench, &param); err != nil {
+		return frame.Failf("failed to load params, err: %v", err)
+	}
+	if param.TxNum == 0 {
+		return frame.Failf("tx number should be greater than 0")
+	}
+	amount := new(big.Int).SetUint64(param.Amount)
+	if param.Amount == 0 {
+		amount = big.NewInt(1)
+	}
+
+	sender, err := senderAccount(ctx)
+	if err != nil {
+		return frame.Failf("generate sender account failed, err: %v", err)
+	}
+	var to common.Address
+	if _, err := rand.Read(to[:]); err != nil {
+		return frame.Failf("failed to generate receiver, err: %v", err)
+	}
+
+	res := frame.NewResult()
+	hashes := make([]common.Hash, 0, param.TxNum)
+	start := time.Now()
+	for i := uint64(0); i < param.TxNum; i++ {
+		tx, err := sender.NewSignedTxContext(ctx, to, amount, nil)
+		if err != nil {
+			return res.Fail(fmt.Errorf("failed to sign tx, err: %v", err))
+		}
+		if err := sender.SendTxContext(ctx, tx); err != nil {
+			return res.Fail(fmt.Errorf("failed to send tx %d, err: %v", i, err))
+		}
+		hashes = append(hashes, tx.Hash())
+		res.AddTx(tx.Hash().Hex())
+	}
+	sent := time.Since(start)
+
+	if err := sender.WaitUntil(ctx, sender.TxsPacked(hashes...), config.Conf.BlockWaitTimeout(param.TxNum)); err != nil {
+		return res.Fail(fmt.Errorf("failed to wait txs packed, err: %v", err))
+	}
+	elapsed := time.Since(start)
+	tps := float64(param.TxNum) / elapsed.Seconds()
+	log.Infof("%s sent %d txs in %v, packed in %v, tps %.2f", sender.Addr().Hex(), param.TxNum, sent, elapsed, tps)
+
+	return res.SetMetric("txs", param.TxNum).SetMetric("tps", tps)
+}
@@ -19,23 +19,72 @@
 package core
 
 import (
+	"time"
+
 	"github.com/dylenfu/zion-tool/pkg/frame"
 	"github.com/dylenfu/zion-tool/pkg/math"
 )
 
+// rpcRetry retries read only methods failed by transient rpc errors, e.g:
+// devnet is still warming up. methods sending txs are not retried, since the
+// tx may have been broadcast before the error and sent twice on retry.
+var rpcRetry = frame.WithRetry(frame.RetryPolicy{
+	MaxAttempts: 3,
+	Backoff:     frame.BackoffExponential,
+	Delay:       2 * time.Second,
+	MaxDelay:    10 * time.Second,
+})
+
 func Endpoint() {
 	math.Init(18)
 
-	frame.Tool.RegMethod("demo", Demo)
+	// every instance of concurrent method sends txs from its own account
+	frame.Tool.SetInstanceSetup(setupInstance)
+
+	frame.Tool.RegMethod("demo", Demo,
+		frame.WithDescription("do nothing, used to check the tool works"),
+		frame.WithTags("smoke"),
+	)
 
 	// ethereum
-	frame.Tool.RegMethod("transfer", Transfer)
-	frame.Tool.RegMethod("header", Header)
+	frame.Tool.RegHandler("transfer", Transfer, frame.Concurrent(),
+		frame.WithDescription("transfer native token from master or instance account and check balances"),
+		frame.WithTags("transfer", "smoke"),
+		frame.WithParams(caseTransfer, TransferParam{}),
+	)
+	frame.Tool.RegHandler("header", Header, rpcRetry,
+		frame.WithDescription("fetch block header and check json encoding"),
+		frame.WithTags("block", "smoke"),
+		frame.WithParams(caseHeader, HeaderParam{}),
+	)
 
 	// epoch related
-	frame.Tool.RegMethod("register", Register)
-	frame.Tool.RegMethod("stake", Stake)
-	frame.Tool.RegMethod("list", NodeList)
+	frame.Tool.RegHandler("register", Register,
+		frame.WithDescription("register nodes as validators with their stake accounts"),
+		frame.WithTags("epoch"),
+		frame.WithParams(caseRegister, RegisterParam{}),
+	)
+	frame.Tool.RegHandler("stake", Stake,
+		frame.DependsOn("register"),
+		frame.WithDescription("stake to validators from master or instance account"),
+		frame.WithTags("epoch"),
+		frame.WithParams(caseStake, StakeParam{}),
+	)
+	frame.Tool.RegHandler("epoch", Epoch, rpcRetry,
+		frame.WithDescription("show current epoch info of node manager"),
+		frame.WithTags("epoch", "smoke"),
+	)
+	frame.Tool.RegMethod("list", NodeList,
+		frame.WithDescription("list validator and stake address of nodes in config"),
+		frame.WithTags("epoch", "smoke"),
+	)
+
+	// benchmark
+	frame.Tool.RegHandler("bench", Bench, frame.Concurrent(),
+		frame.WithDescription("send transfers without waiting each of them and measure tps"),
+		frame.WithTags("bench"),
+		frame.WithParams(caseBench, BenchParam{}),
+	)
 }
 
 func Demo() bool {
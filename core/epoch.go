@@ -19,59 +19,184 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
+	"fmt"
 	"math/big"
 
 	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
 	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/ethereum/go-ethereum/common"
 )
 
-func Register() bool {
-	var param struct {
-		NodeIndexList []int
-		StakeAmount   int
+const (
+	caseRegister = "test_register.json"
+	caseStake    = "test_stake.json"
+
+	// stateValidators is the run state key of validators registered by `register`
+	stateValidators = "validators"
+)
+
+type RegisterParam struct {
+	NodeIndexList []int `desc:"index of nodes in config to be registered as validator"`
+	StakeAmount   int   `desc:"init stake amount in ether"`
+}
+
+type StakeParam struct {
+	NodeIndexList []int `desc:"index of validator nodes, default to validators registered in run state"`
+	StakeAmount   int   `desc:"stake amount in ether"`
+}
+
+type registeredValidator struct {
+	NodeIndex int
+	Validator common.Address
+	StakeAddr common.Address
+}
+
+func registeredValidators(ctx context.Context) ([]*registeredValidator, error) {
+	vals := make([]*registeredValidator, 0)
+	if _, err := frame.StateFrom(ctx).Get(stateValidators, &vals); err != nil {
+		return nil, err
+	}
+	return vals, nil
+}
+
+// saveValidator appends validator to run state, so that it can be staked by
+// following methods or the next run.
+func saveValidator(ctx context.Context, val *registeredValidator) error {
+	vals, err := registeredValidators(ctx)
+	if err != nil {
+		return err
 	}
+	for _, v := range vals {
+		if v.NodeIndex == val.NodeIndex {
+			return nil
+		}
+	}
+	return frame.StateFrom(ctx).Set(stateValidators, append(vals, val))
+}
 
-	if err := config.LoadParams("test_register.json", &param); err != nil {
-		log.Errorf("failed to load params, err: %v", err)
-		return false
+func Register(ctx context.Context) *frame.Result {
+	var param RegisterParam
+
+	if err := loadParams(ctx, caseRegister, &param); err != nil {
+		return frame.Failf("failed to load params, err: %v", err)
 	}
 
 	log.Split("start to change epoch")
 
 	log.Split("start to prepare balance")
-	if err := prepareBalance(); err != nil {
-		log.Errorf("failed to prepare balance, err: %v", err)
-		return false
+	if err := prepareBalance(ctx); err != nil {
+		return frame.Failf("failed to prepare balance, err: %v", err)
 	}
 
 	vals, err := generateStakeAccounts(param.NodeIndexList)
 	if err != nil {
-		log.Errorf("failed to generate proposer, err: %v", err)
-		return false
+		return frame.Failf("failed to generate proposer, err: %v", err)
+	}
+	if len(vals) == 0 {
+		return frame.Failf("no node to register")
 	}
 
 	log.Split("start to register nodes")
+	res := frame.NewResult()
 	stakeAmt := new(big.Int).Mul(big.NewInt(int64(param.StakeAmount)), ETH1)
-	for _, v := range vals {
-		balance, err := v.Balance(nil)
+	for i, v := range vals {
+		balance, err := v.BalanceContext(ctx, nil)
 		if err != nil {
-			return false
+			return res.Fail(fmt.Errorf("failed to get stake account %s balance, err: %v", v.Addr().Hex(), err))
 		} else {
 			log.Infof("stake account %s balance %v", v.Addr().Hex(), balance)
 		}
-		if hash, err := v.Register(v.Address, stakeAmt, v.StakeAddr.Hex()); err != nil {
-			log.Errorf("failed to register account, hash %s, err: %v", hash.Hex(), err)
-			return false
+		hash, err := v.RegisterContext(ctx, v.Address, stakeAmt, v.StakeAddr.Hex())
+		if err != nil {
+			return res.Fail(fmt.Errorf("failed to register account, hash %s, err: %v", hash.Hex(), err))
+		}
+		res.AddTx(hash.Hex())
+		if err := saveValidator(ctx, &registeredValidator{
+			NodeIndex: param.NodeIndexList[i],
+			Validator: v.Address,
+			StakeAddr: v.StakeAddr,
+		}); err != nil {
+			return res.Fail(fmt.Errorf("failed to save validator, err: %v", err))
 		}
 	}
 
-	wait()
+	if err := waitBlocks(ctx, vals[len(vals)-1], 1); err != nil {
+		return res.Fail(err)
+	}
 
-	return true
+	return res.SetMetric("validators", len(vals))
 }
 
-func Stake() bool {
-	return true
+// Stake stakes to the validators in NodeIndexList from master account, or
+// the ephemeral account of running instance. validators registered in the run
+// state are used if the list is empty.
+func Stake(ctx context.Context) *frame.Result {
+	var param StakeParam
+
+	if err := loadParams(ctx, caseStake, &param); err != nil {
+		return frame.Failf("failed to load params, err: %v", err)
+	}
+
+	indexList := param.NodeIndexList
+	if len(indexList) == 0 {
+		vals, err := registeredValidators(ctx)
+		if err != nil {
+			return frame.Failf("failed to load registered validators, err: %v", err)
+		}
+		for _, v := range vals {
+			indexList = append(indexList, v.NodeIndex)
+		}
+	}
+	if len(indexList) == 0 {
+		return frame.Failf("no validator to stake")
+	}
+
+	sender, err := senderAccount(ctx)
+	if err != nil {
+		return frame.Failf("generate sender account failed, err: %v", err)
+	}
+
+	res := frame.NewResult()
+	amount := new(big.Int).Mul(big.NewInt(int64(param.StakeAmount)), ETH1)
+	for _, index := range indexList {
+		if index < 0 || index >= len(config.Conf.Nodes) {
+			return res.Fail(fmt.Errorf("node index %d out of range", index))
+		}
+		validator := config.Conf.Nodes[index].Address
+		hash, err := sender.StakeContext(ctx, validator, amount)
+		if err != nil {
+			return res.Fail(fmt.Errorf("failed to stake %v to %s, hash %s, err: %v", amount, validator.Hex(), hash.Hex(), err))
+		}
+		res.AddTx(hash.Hex())
+		log.Infof("%s stake %v to validator %s, hash %s", sender.Addr().Hex(), amount, validator.Hex(), hash.Hex())
+	}
+
+	if err := waitBlocks(ctx, sender, 1); err != nil {
+		return res.Fail(err)
+	}
+
+	return res.SetMetric("validators", len(indexList))
+}
+
+// Epoch logs the current epoch info of node manager
+func Epoch(ctx context.Context) *frame.Result {
+	acc, err := masterAccount()
+	if err != nil {
+		return frame.Failf("generate master account failed, err: %v", err)
+	}
+	epoch, err := acc.EpochContext(ctx)
+	if err != nil {
+		return frame.Failf("failed to get current epoch, err: %v", err)
+	}
+	enc, err := json.MarshalIndent(epoch, "", "  ")
+	if err != nil {
+		return frame.Failf("failed to marshal epoch, err: %v", err)
+	}
+	log.Infof("current epoch %s", enc)
+	return frame.Succeed()
 }
 
 func NodeList() bool {
@@ -19,97 +19,119 @@
 package core
 
 import (
+	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
 	"github.com/dylenfu/zion-tool/pkg/log"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 )
 
-func Transfer() bool {
-	var param struct {
-		To     []string
-		Amount uint64
-	}
+const (
+	caseTransfer = "test_transfer.json"
+	caseHeader   = "test_header.json"
+)
+
+type TransferParam struct {
+	To     []string `desc:"receiver addresses"`
+	Amount uint64   `desc:"amount of each transfer in ether"`
+}
+
+type HeaderParam struct {
+	Height uint64 `desc:"block height"`
+}
 
-	if err := config.LoadParams("test_transfer.json", &param); err != nil {
-		log.Errorf("failed to load params, err: %v", err)
-		return false
+func Transfer(ctx context.Context) *frame.Result {
+	var param TransferParam
+
+	if err := loadParams(ctx, caseTransfer, &param); err != nil {
+		return frame.Failf("failed to load params, err: %v", err)
 	}
 
-	acc, err := masterAccount()
+	acc, err := senderAccount(ctx)
 	if err != nil {
-		log.Errorf("generate master account failed, err: %v", err)
+		return frame.Failf("generate sender account failed, err: %v", err)
 	}
+
+	// concurrent instances may pay the same receivers, so that the balance
+	// could grow by more than the amount. the successful receipt is the proof
+	// of the instance's own transfer, and the balance is checked not less than
+	// expected only.
+	inst := frame.InstanceFrom(ctx)
+	shared := inst != nil && inst.Total > 1
+
+	res := frame.NewResult()
 	for _, to := range param.To {
 		to := common.HexToAddress(to)
 		amount := new(big.Int).Mul(ETH1, new(big.Int).SetUint64(param.Amount))
 
-		balanceBeforeTransfer, err := acc.BalanceOf(to, nil)
+		balanceBeforeTransfer, err := acc.BalanceOfContext(ctx, to, nil)
 		if err != nil {
-			log.Errorf("failed to get balance before transfer, err: %v", err)
-			return false
+			return res.Fail(fmt.Errorf("failed to get balance before transfer, err: %v", err))
 		} else {
 			log.Infof("balance before transfer %s", balanceBeforeTransfer.String())
 		}
 
-		if tx, err := acc.Transfer(to, amount); err != nil {
-			log.Errorf("failed to transfer eth, err: %v", err)
-			return false
+		if receipt, err := acc.TransferContext(ctx, to, amount); err != nil {
+			return res.Fail(fmt.Errorf("failed to transfer eth, err: %v", err))
 		} else {
-			log.Infof("%s transfer %s to %s, tx hash %s", acc.Addr().Hex(), amount.String(), to.Hex(), tx.Hex())
+			res.AddTx(receipt.TxHash.Hex())
+			log.Infof("%s transfer %s to %s, tx hash %s, gas used %d", acc.Addr().Hex(), amount.String(), to.Hex(), receipt.TxHash.Hex(), receipt.GasUsed)
 		}
 
-		balanceAfterTransfer, err := acc.BalanceOf(to, nil)
-		if err != nil {
-			log.Errorf("failed to get balance before transfer, err: %v", err)
-			return false
+		// the node behind url may lag, wait until the balance changed
+		expect := new(big.Int).Add(balanceBeforeTransfer, amount)
+		balanceAfterTransfer := new(big.Int)
+		if err := acc.WaitUntil(ctx, func(ctx context.Context, height uint64) (bool, error) {
+			balance, err := acc.BalanceOfContext(ctx, to, nil)
+			if err != nil {
+				return false, err
+			}
+			balanceAfterTransfer = balance
+			return balance.Cmp(expect) >= 0, nil
+		}, config.Conf.BlockWaitTimeout(1)); err != nil {
+			return res.Fail(fmt.Errorf("failed to get balance after transfer, err: %v", err))
 		} else {
 			log.Infof("balance after transfer %s", balanceAfterTransfer.String())
 		}
 
-		if balanceAfterTransfer.Cmp(new(big.Int).Add(balanceBeforeTransfer, amount)) != 0 {
-			log.Error("balance not match")
-			return false
+		if !shared && balanceAfterTransfer.Cmp(expect) != 0 {
+			return res.Fail(fmt.Errorf("balance not match, before %v, after %v, amount %v",
+				balanceBeforeTransfer, balanceAfterTransfer, amount))
 		}
 		log.Split()
 	}
 
-	return true
+	return res.SetMetric("transfers", len(param.To))
 }
 
-func Header() bool {
-	var param struct {
-		Height uint64
-	}
+func Header(ctx context.Context) *frame.Result {
+	var param HeaderParam
 
-	if err := config.LoadParams("test_header.json", &param); err != nil {
-		log.Errorf("failed to load params, err: %v", err)
-		return false
+	if err := loadParams(ctx, caseHeader, &param); err != nil {
+		return frame.Failf("failed to load params, err: %v", err)
 	}
 
 	cli, err := masterAccount()
 	if err != nil {
-		log.Errorf("failed to generate client, err: %v", err)
-		return false
+		return frame.Failf("failed to generate client, err: %v", err)
 	}
 
-	header, err := cli.BlockHeaderByNumber(param.Height)
+	header, err := cli.BlockHeaderByNumberContext(ctx, param.Height)
 	if err != nil {
-		log.Errorf("failed to get header, err: %v", err)
-		return false
+		return frame.Failf("failed to get header, err: %v", err)
 	}
 	blob, err := header.MarshalJSON()
 	if err != nil {
-		log.Errorf("failed to marshal header, err: %v", err)
-		return false
+		return frame.Failf("failed to marshal header, err: %v", err)
 	}
 	if err := new(types.Header).UnmarshalJSON(blob); err != nil {
-		log.Errorf("failed to unmarshal header, err: %v", err)
-		return false
+		return frame.Failf("failed to unmarshal header, err: %v", err)
 	}
 	log.Infof("header json and hexutil format: %s", hexutil.Encode(blob))
-	return true
+	return frame.Succeed()
 }
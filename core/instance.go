@@ -0,0 +1,59 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/dylenfu/zion-tool/pkg/sdk"
+)
+
+type instanceAccountKey struct{}
+
+// setupInstance creates an ephemeral account for method instance and funds it
+// with InitBalance from master account.
+func setupInstance(ctx context.Context, inst *frame.Instance) (context.Context, error) {
+	if len(config.Conf.Nodes) == 0 {
+		return ctx, fmt.Errorf("no node in config")
+	}
+	url := config.Conf.Nodes[(inst.Index-1)%len(config.Conf.Nodes)].Url
+	acc, err := sdk.NewAccountContext(ctx, config.Conf.ChainID, url)
+	if err != nil {
+		return ctx, fmt.Errorf("failed to create account, err: %v", err)
+	}
+
+	amount := new(big.Int).Mul(big.NewInt(int64(config.Conf.InitBalance)), ETH1)
+	if err := fund(ctx, acc, amount); err != nil {
+		return ctx, fmt.Errorf("failed to fund account %s, err: %v", acc.Addr().Hex(), err)
+	}
+	log.Infof("instance %d/%d account %s funded %v", inst.Index, inst.Total, acc.Addr().Hex(), amount)
+
+	// ephemeral account is not a node in config
+	return context.WithValue(ctx, instanceAccountKey{}, &Account{
+		Node:    &config.Node{Url: url},
+		Account: acc,
+	}), nil
+}
+
+// fund transfers amount from master account, the concurrent instances share
+// the nonce manager of master account.
+func fund(ctx context.Context, acc *sdk.Account, amount *big.Int) error {
+	master, err := masterAccount()
+	if err != nil {
+		return err
+	}
+	_, err = master.TransferContext(ctx, acc.Addr(), amount)
+	return err
+}
+
+// senderAccount returns the ephemeral account of the running instance, and
+// master account if the method runs as a single instance.
+func senderAccount(ctx context.Context) (*Account, error) {
+	if acc, ok := ctx.Value(instanceAccountKey{}).(*Account); ok {
+		return acc, nil
+	}
+	return masterAccount()
+}
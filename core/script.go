@@ -0,0 +1,324 @@
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/dop251/goja"
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/files"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/dylenfu/zion-tool/pkg/sdk"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+const (
+	scriptDir = "scripts"
+	scriptExt = ".js"
+	scriptTag = "script"
+	// scriptEntry is the optional function called with params after the
+	// script evaluated, e.g: function main(params) { ... }
+	scriptEntry = "main"
+)
+
+// LoadScripts registers the js files in `Workspace/scripts` as methods, the
+// method name is the file name without extension and the leading comment is
+// the description. params are read from the step or `cases/<name>.json`.
+func LoadScripts() error {
+	dir := files.FullPath(config.Conf.Workspace, scriptDir, "")
+	list, err := filepath.Glob(filepath.Join(dir, "*"+scriptExt))
+	if err != nil {
+		return err
+	}
+	for _, path := range list {
+		name := strings.TrimSuffix(filepath.Base(path), scriptExt)
+		if _, err := frame.Tool.Describe(name); err == nil {
+			log.Errorf("script %s conflicts with registered method, skip it", path)
+			continue
+		}
+
+		src, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		prg, err := goja.Compile(path, string(src), false)
+		if err != nil {
+			return fmt.Errorf("failed to compile script %s, err: %v", path, err)
+		}
+		frame.Tool.RegHandler(name, scriptHandler(name, prg),
+			frame.WithDescription(scriptDescription(src)),
+			frame.WithTags(scriptTag),
+		)
+		log.Debugf("load script %s as method %s", path, name)
+	}
+	return nil
+}
+
+// scriptDescription returns the leading `//` comment lines of script
+func scriptDescription(src []byte) string {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(string(src)))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "//") {
+			break
+		}
+		lines = append(lines, strings.TrimSpace(strings.TrimPrefix(line, "//")))
+	}
+	return strings.Join(lines, " ")
+}
+
+// scriptHandler evaluates the script in a fresh runtime on every call, since
+// goja runtime is not safe for concurrent use. the script fails if it throws
+// or the entry function returns false.
+func scriptHandler(name string, prg *goja.Program) frame.Handler {
+	return func(ctx context.Context) *frame.Result {
+		params, err := scriptParams(ctx, name)
+		if err != nil {
+			return frame.Failf("failed to load params, err: %v", err)
+		}
+
+		res := frame.NewResult()
+		vm := goja.New()
+		vm.SetFieldNameMapper(goja.UncapFieldNameMapper())
+		newScriptEnv(ctx, vm, res).bind(params)
+
+		// interrupt the runtime on timeout
+		done := make(chan struct{})
+		defer close(done)
+		go func() {
+			select {
+			case <-ctx.Done():
+				vm.Interrupt(ctx.Err())
+			case <-done:
+			}
+		}()
+
+		if _, err := vm.RunProgram(prg); err != nil {
+			return res.Fail(scriptError(err))
+		}
+		entry, ok := goja.AssertFunction(vm.Get(scriptEntry))
+		if !ok {
+			return res
+		}
+		ret, err := entry(goja.Undefined(), vm.Get("params"))
+		if err != nil {
+			return res.Fail(scriptError(err))
+		}
+		if ret != nil && !goja.IsUndefined(ret) && !goja.IsNull(ret) && !ret.ToBoolean() {
+			return res.Fail(fmt.Errorf("script %s returned false", name))
+		}
+		return res
+	}
+}
+
+func scriptParams(ctx context.Context, name string) (interface{}, error) {
+	var params interface{}
+	if raw := frame.StepParams(ctx); len(raw) > 0 {
+		return params, json.Unmarshal(raw, &params)
+	}
+	caseFile := name + ".json"
+	if _, err := os.Stat(files.FullPath(config.Conf.Workspace, "cases", caseFile)); err != nil {
+		return nil, nil
+	}
+	return params, loadParams(ctx, caseFile, &params)
+}
+
+func scriptError(err error) error {
+	if ex, ok := err.(*goja.Exception); ok {
+		return fmt.Errorf("script exception: %s", ex.Error())
+	}
+	if ie, ok := err.(*goja.InterruptedError); ok {
+		return fmt.Errorf("script interrupted: %v", ie.Value())
+	}
+	return err
+}
+
+// scriptEnv holds the globals exposed to script:
+//
+//	params            step params or case file content, null if absent
+//	chainID, nodes    chain id and nodes in config
+//	log               debug, info, warn and error
+//	master()          master account
+//	account(index)    stake account of node
+//	sender()          instance account, or master account
+//	newAccount()      ephemeral account without balance
+//	state             get(key) and set(key, value) of run state
+//	result            addTx(hash) and metric(key, value)
+//	sleep(ms)
+type scriptEnv struct {
+	ctx context.Context
+	vm  *goja.Runtime
+	res *frame.Result
+}
+
+func newScriptEnv(ctx context.Context, vm *goja.Runtime, res *frame.Result) *scriptEnv {
+	return &scriptEnv{ctx: ctx, vm: vm, res: res}
+}
+
+type scriptNode struct {
+	Index     int
+	Url       string
+	Address   string
+	StakeAddr string
+}
+
+func (e *scriptEnv) bind(params interface{}) {
+	nodes := make([]*scriptNode, 0, len(config.Conf.Nodes))
+	for i, node := range config.Conf.Nodes {
+		nodes = append(nodes, &scriptNode{
+			Index:     i,
+			Url:       node.Url,
+			Address:   node.Address.Hex(),
+			StakeAddr: node.StakeAddr.Hex(),
+		})
+	}
+
+	state := frame.StateFrom(e.ctx)
+	globals := map[string]interface{}{
+		"params":  params,
+		"chainID": config.Conf.ChainID,
+		"nodes":   nodes,
+		"log": map[string]interface{}{
+			"debug": func(a ...interface{}) { log.Debug(a...) },
+			"info":  func(a ...interface{}) { log.Info(a...) },
+			"warn":  func(a ...interface{}) { log.Warn(a...) },
+			"error": func(a ...interface{}) { log.Error(a...) },
+		},
+		"master": func() (*scriptAccount, error) {
+			return e.account(masterAccount())
+		},
+		"account": func(index int) (*scriptAccount, error) {
+			return e.account(generateStakeAccount(index))
+		},
+		"sender": func() (*scriptAccount, error) {
+			return e.account(senderAccount(e.ctx))
+		},
+		"newAccount": func() (*scriptAccount, error) {
+			if len(config.Conf.Nodes) == 0 {
+				return nil, fmt.Errorf("no node in config")
+			}
+			acc, err := sdk.NewAccountContext(e.ctx, config.Conf.ChainID, config.Conf.Nodes[0].Url)
+			if err != nil {
+				return nil, err
+			}
+			return e.account(&Account{Node: &config.Node{Url: config.Conf.Nodes[0].Url}, Account: acc}, nil)
+		},
+		"state": map[string]interface{}{
+			"get": func(key string) (interface{}, error) {
+				var v interface{}
+				_, err := state.Get(key, &v)
+				return v, err
+			},
+			"set": func(key string, value interface{}) error {
+				return state.Set(key, value)
+			},
+		},
+		"result": map[string]interface{}{
+			"addTx":  func(hash string) { e.res.AddTx(hash) },
+			"metric": func(key string, value interface{}) { e.res.SetMetric(key, value) },
+		},
+		"sleep": func(ms int64) error {
+			select {
+			case <-e.ctx.Done():
+				return e.ctx.Err()
+			case <-time.After(time.Duration(ms) * time.Millisecond):
+				return nil
+			}
+		},
+	}
+	for name, v := range globals {
+		e.vm.Set(name, v)
+	}
+}
+
+func (e *scriptEnv) account(acc *Account, err error) (*scriptAccount, error) {
+	if err != nil {
+		return nil, err
+	}
+	return &scriptAccount{ctx: e.ctx, res: e.res, acc: acc}, nil
+}
+
+// scriptAccount exposes sdk account to script with lower camel case methods,
+// amounts are in ether and the txs sent are captured in the result.
+type scriptAccount struct {
+	ctx context.Context
+	res *frame.Result
+	acc *Account
+}
+
+func (a *scriptAccount) Address() string {
+	return a.acc.Addr().Hex()
+}
+
+// Balance returns balance of addr in wei, default to the account itself
+func (a *scriptAccount) Balance(addr string) (string, error) {
+	target := a.acc.Addr()
+	if addr != "" {
+		target = common.HexToAddress(addr)
+	}
+	balance, err := a.acc.BalanceOfContext(a.ctx, target, nil)
+	if err != nil {
+		return "", err
+	}
+	return balance.String(), nil
+}
+
+func (a *scriptAccount) Transfer(to string, amount int64) (string, error) {
+	receipt, err := a.acc.TransferContext(a.ctx, common.HexToAddress(to), a.ether(amount))
+	if receipt == nil {
+		return "", err
+	}
+	return a.captured(receipt.TxHash, err)
+}
+
+func (a *scriptAccount) Register(validator string, amount int64, desc string) (string, error) {
+	hash, err := a.acc.RegisterContext(a.ctx, common.HexToAddress(validator), a.ether(amount), desc)
+	return a.captured(hash, err)
+}
+
+func (a *scriptAccount) Stake(validator string, amount int64) (string, error) {
+	hash, err := a.acc.StakeContext(a.ctx, common.HexToAddress(validator), a.ether(amount))
+	return a.captured(hash, err)
+}
+
+func (a *scriptAccount) Epoch() (interface{}, error) {
+	return a.acc.EpochContext(a.ctx)
+}
+
+func (a *scriptAccount) BlockNumber() (uint64, error) {
+	return a.acc.CurrentBlockNumberContext(a.ctx)
+}
+
+func (a *scriptAccount) Header(height uint64) (*types.Header, error) {
+	return a.acc.BlockHeaderByNumberContext(a.ctx, height)
+}
+
+func (a *scriptAccount) Receipt(hash string) (*types.Receipt, error) {
+	return a.acc.GetReceiptContext(a.ctx, common.HexToHash(hash))
+}
+
+func (a *scriptAccount) WaitBlocks(n uint64) (uint64, error) {
+	return a.acc.WaitBlocks(a.ctx, n, config.Conf.BlockWaitTimeout(n))
+}
+
+func (a *scriptAccount) ether(amount int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(amount), ETH1)
+}
+
+func (a *scriptAccount) captured(hash common.Hash, err error) (string, error) {
+	if err == nil && hash != sdk.EmptyHash {
+		a.res.AddTx(hash.Hex())
+	}
+	return hash.Hex(), err
+}
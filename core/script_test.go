@@ -0,0 +1,91 @@
+package core
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+)
+
+func TestLoadScripts(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	scripts := map[string]string{
+		"script_ok.js":    "// check params\n// in script\nfunction main(params) { result.metric('x', params.x); return params.x == 1 }",
+		"script_false.js": "function main(params) { return false }",
+		"script_throw.js": "throw new Error('boom')",
+		"not_script.txt":  "ignored",
+	}
+	if err := os.MkdirAll(filepath.Join(dir, scriptDir), 0755); err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range scripts {
+		if err := ioutil.WriteFile(filepath.Join(dir, scriptDir, name), []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	conf := config.Conf
+	defer func() { config.Conf = conf }()
+	config.Conf = &config.Config{Workspace: dir}
+
+	if err := LoadScripts(); err != nil {
+		t.Fatal(err)
+	}
+	info, err := frame.Tool.Describe("script_ok")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Description != "check params in script" || len(info.Tags) != 1 || info.Tags[0] != scriptTag {
+		t.Fatalf("unexpected method info %+v", info)
+	}
+	if _, err := frame.Tool.Describe("not_script"); err == nil {
+		t.Fatal("expect non js file skipped")
+	}
+
+	frame.Tool.SetInterval(0)
+	frame.Tool.StartSteps([]*frame.Step{
+		{Name: "script_ok", Method: "script_ok", Params: json.RawMessage(`{"x":1}`)},
+		{Name: "script_false", Method: "script_false"},
+		{Name: "script_throw", Method: "script_throw"},
+	})
+
+	expect := map[string]frame.Status{
+		"script_ok":    frame.StatusSuccess,
+		"script_false": frame.StatusFailed,
+		"script_throw": frame.StatusFailed,
+	}
+	report := frame.Tool.Report()
+	if report == nil || len(report.Methods) != len(expect) {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	for _, m := range report.Methods {
+		if m.Status != expect[m.Name] {
+			t.Errorf("method %s expect %s, got %s, %s", m.Name, expect[m.Name], m.Status, m.Message)
+		}
+	}
+}
+
+func TestScriptDescription(t *testing.T) {
+	var testdata = []struct {
+		src    string
+		expect string
+	}{
+		{"// transfer to nodes\n//   and check balance\nvar a = 1", "transfer to nodes and check balance"},
+		{"var a = 1\n// not leading", ""},
+		{"", ""},
+	}
+	for _, v := range testdata {
+		if got := scriptDescription([]byte(v.src)); got != v.expect {
+			t.Errorf("expect %q, got %q", v.expect, got)
+		}
+	}
+}
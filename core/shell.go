@@ -0,0 +1,389 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/files"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/math"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/peterh/liner"
+)
+
+const (
+	shellPrompt      = "zion> "
+	shellHistoryFile = ".zion_history"
+	// shellTimeout limits every sdk command, methods run with their own timeout
+	shellTimeout = time.Minute
+)
+
+type shellCommand struct {
+	usage string
+	help  string
+	run   func(ctx context.Context, args []string) error
+}
+
+// Shell is the interactive mode which keeps the node connections open, and
+// runs registered methods or sdk operations with history and tab completion.
+type Shell struct {
+	line     *liner.State
+	commands map[string]*shellCommand
+	current  int
+	history  string
+}
+
+func NewShell() *Shell {
+	s := &Shell{
+		history: files.FullPath(config.Conf.Workspace, "", shellHistoryFile),
+	}
+	s.commands = map[string]*shellCommand{
+		"help":     {"help", "show commands", s.help},
+		"methods":  {"methods", "list registered methods", s.methods},
+		"describe": {"describe <method>", "describe method and its params", s.describe},
+		"run":      {"run <selectors...> | run <method> <json params>", "run methods, e.g: run tag:smoke, run transfer {\"To\":[\"0x..\"],\"Amount\":1}", s.runMethods},
+		"use":      {"use <node index>", "switch the account to the stake account of node", s.use},
+		"nodes":    {"nodes", "list nodes in config", s.nodes},
+		"height":   {"height", "show current block number", s.height},
+		"balance":  {"balance [address]", "show balance of address, default to current account", s.balance},
+		"transfer": {"transfer <to> <amount>", "transfer amount in ether from current account", s.transfer},
+		"epoch":    {"epoch", "show current epoch info", s.epoch},
+		"header":   {"header [height]", "show block header, default to the latest one", s.header},
+		"receipt":  {"receipt <tx hash>", "show transaction receipt", s.receipt},
+		"exit":     {"exit", "quit the shell", nil},
+	}
+	return s
+}
+
+// Run reads and executes commands until `exit` or EOF
+func (s *Shell) Run() error {
+	s.line = liner.NewLiner()
+	defer s.line.Close()
+
+	s.line.SetCtrlCAborts(true)
+	s.line.SetCompleter(s.complete)
+	if f, err := os.Open(s.history); err == nil {
+		s.line.ReadHistory(f)
+		f.Close()
+	}
+	defer s.saveHistory()
+
+	fmt.Println("Zion tool shell, type `help` to show commands")
+	for {
+		input, err := s.line.Prompt(shellPrompt)
+		if err == liner.ErrPromptAborted {
+			continue
+		}
+		if err == io.EOF {
+			fmt.Println()
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		s.line.AppendHistory(input)
+
+		exit, err := s.execute(context.Background(), input)
+		if exit {
+			return nil
+		}
+		if err != nil {
+			fmt.Printf("error: %v\n", err)
+		}
+	}
+}
+
+// execute dispatches the input line to command, exit is true for `exit` or `quit`
+func (s *Shell) execute(ctx context.Context, input string) (exit bool, err error) {
+	fields := strings.Fields(input)
+	if len(fields) == 0 {
+		return false, nil
+	}
+	name, args := fields[0], fields[1:]
+	if name == "exit" || name == "quit" {
+		return true, nil
+	}
+	cmd, ok := s.commands[name]
+	if !ok || cmd.run == nil {
+		return false, fmt.Errorf("unknown command %s, type `help` to show commands", name)
+	}
+	return false, cmd.run(ctx, args)
+}
+
+func (s *Shell) saveHistory() {
+	f, err := os.Create(s.history)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	s.line.WriteHistory(f)
+}
+
+// complete completes command names, and method names or tags for `run` and `describe`
+func (s *Shell) complete(line string) []string {
+	fields := strings.Fields(line)
+	if len(fields) == 0 || (len(fields) == 1 && !strings.HasSuffix(line, " ")) {
+		prefix := strings.TrimSpace(line)
+		list := make([]string, 0)
+		for name := range s.commands {
+			if strings.HasPrefix(name, prefix) {
+				list = append(list, name+" ")
+			}
+		}
+		sort.Strings(list)
+		return list
+	}
+
+	if fields[0] != "run" && fields[0] != "describe" {
+		return nil
+	}
+	prefix := ""
+	if !strings.HasSuffix(line, " ") {
+		prefix = fields[len(fields)-1]
+	}
+	head := line[:len(line)-len(prefix)]
+
+	candidates := make([]string, 0)
+	tags := make(map[string]bool)
+	for _, info := range frame.Tool.Methods() {
+		candidates = append(candidates, info.Name)
+		for _, tag := range info.Tags {
+			if !tags[tag] && fields[0] == "run" {
+				tags[tag] = true
+				candidates = append(candidates, "tag:"+tag)
+			}
+		}
+	}
+	list := make([]string, 0)
+	for _, c := range candidates {
+		if strings.HasPrefix(c, prefix) {
+			list = append(list, head+c)
+		}
+	}
+	return list
+}
+
+// account returns the stake account of current node, stake accounts are
+// cached so that connections are kept open during the whole shell session.
+func (s *Shell) account() (*Account, error) {
+	return generateStakeAccount(s.current)
+}
+
+func (s *Shell) help(ctx context.Context, args []string) error {
+	names := make([]string, 0, len(s.commands))
+	for name := range s.commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		cmd := s.commands[name]
+		fmt.Printf("  %-50s %s\n", cmd.usage, cmd.help)
+	}
+	return nil
+}
+
+func (s *Shell) methods(ctx context.Context, args []string) error {
+	frame.Tool.PrintMethods()
+	return nil
+}
+
+func (s *Shell) describe(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s", s.commands["describe"].usage)
+	}
+	return frame.Tool.PrintMethod(args[0])
+}
+
+func (s *Shell) runMethods(ctx context.Context, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: %s", s.commands["run"].usage)
+	}
+
+	// inline json params follows the method name
+	if len(args) > 1 && strings.HasPrefix(args[1], "{") {
+		params := strings.Join(args[1:], " ")
+		if !json.Valid([]byte(params)) {
+			return fmt.Errorf("invalid json params %s", params)
+		}
+		if _, err := frame.Tool.Describe(args[0]); err != nil {
+			return err
+		}
+		frame.Tool.StartSteps([]*frame.Step{{Name: args[0], Method: args[0], Params: json.RawMessage(params)}})
+		return nil
+	}
+
+	methods, err := frame.Tool.Select(args)
+	if err != nil {
+		return err
+	}
+	frame.Tool.Start(methods)
+	return nil
+}
+
+func (s *Shell) use(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s", s.commands["use"].usage)
+	}
+	index, err := strconv.Atoi(args[0])
+	if err != nil || index < 0 || index >= len(config.Conf.Nodes) {
+		return fmt.Errorf("invalid node index %s", args[0])
+	}
+	s.current = index
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+	fmt.Printf("current account %s, node %s\n", acc.Addr().Hex(), acc.Account.Url())
+	return nil
+}
+
+func (s *Shell) nodes(ctx context.Context, args []string) error {
+	for i, node := range config.Conf.Nodes {
+		mark := " "
+		if i == s.current {
+			mark = "*"
+		}
+		fmt.Printf("%s %d. %s validator %s stake address %s\n", mark, i, node.Url, node.Address.Hex(), node.StakeAddr.Hex())
+	}
+	return nil
+}
+
+func (s *Shell) height(ctx context.Context, args []string) error {
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(ctx, shellTimeout)
+	defer cancel()
+	height, err := acc.CurrentBlockNumberContext(ctx)
+	if err != nil {
+		return err
+	}
+	fmt.Println(height)
+	return nil
+}
+
+func (s *Shell) balance(ctx context.Context, args []string) error {
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+	addr := acc.Addr()
+	if len(args) > 0 {
+		if !common.IsHexAddress(args[0]) {
+			return fmt.Errorf("invalid address %s", args[0])
+		}
+		addr = common.HexToAddress(args[0])
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shellTimeout)
+	defer cancel()
+	balance, err := acc.BalanceOfContext(ctx, addr, nil)
+	if err != nil {
+		return err
+	}
+	fmt.Printf("%s %s wei (%v ether)\n", addr.Hex(), balance, math.PrintFT(math.DecimalFromBigInt(balance)))
+	return nil
+}
+
+func (s *Shell) transfer(ctx context.Context, args []string) error {
+	if len(args) != 2 || !common.IsHexAddress(args[0]) {
+		return fmt.Errorf("usage: %s", s.commands["transfer"].usage)
+	}
+	amount, ok := new(big.Int).SetString(args[1], 10)
+	if !ok {
+		return fmt.Errorf("invalid amount %s", args[1])
+	}
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shellTimeout)
+	defer cancel()
+	receipt, err := acc.TransferContext(ctx, common.HexToAddress(args[0]), new(big.Int).Mul(amount, ETH1))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("tx hash %s, block %d, gas used %d, effective gas price %v\n", receipt.TxHash.Hex(), receipt.BlockNumber, receipt.GasUsed, receipt.EffectiveGasPrice)
+	return nil
+}
+
+func (s *Shell) epoch(ctx context.Context, args []string) error {
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shellTimeout)
+	defer cancel()
+	epoch, err := acc.EpochContext(ctx)
+	if err != nil {
+		return err
+	}
+	return printJson(epoch)
+}
+
+func (s *Shell) header(ctx context.Context, args []string) error {
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shellTimeout)
+	defer cancel()
+	var height uint64
+	if len(args) > 0 {
+		if height, err = strconv.ParseUint(args[0], 10, 64); err != nil {
+			return fmt.Errorf("invalid height %s", args[0])
+		}
+	} else if height, err = acc.CurrentBlockNumberContext(ctx); err != nil {
+		return err
+	}
+	header, err := acc.BlockHeaderByNumberContext(ctx, height)
+	if err != nil {
+		return err
+	}
+	return printJson(header)
+}
+
+func (s *Shell) receipt(ctx context.Context, args []string) error {
+	if len(args) != 1 {
+		return fmt.Errorf("usage: %s", s.commands["receipt"].usage)
+	}
+	acc, err := s.account()
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, shellTimeout)
+	defer cancel()
+	receipt, err := acc.GetReceiptContext(ctx, common.HexToHash(args[0]))
+	if err != nil {
+		return err
+	}
+	return printJson(receipt)
+}
+
+func printJson(v interface{}) error {
+	enc, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(enc))
+	return nil
+}
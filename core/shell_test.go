@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+)
+
+func TestShellExecute(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	conf := config.Conf
+	defer func() { config.Conf = conf }()
+	config.Conf = &config.Config{Workspace: dir, Nodes: []*config.Node{{Url: "http://127.0.0.1:22000"}}}
+
+	var params json.RawMessage
+	frame.Tool.SetInterval(0)
+	frame.Tool.RegHandler("shell_method", func(ctx context.Context) *frame.Result {
+		params = frame.StepParams(ctx)
+		return frame.Succeed()
+	})
+
+	var testdata = []struct {
+		input string
+		exit  bool
+		err   bool
+	}{
+		{"help", false, false},
+		{"methods", false, false},
+		{"describe shell_method", false, false},
+		{"describe", false, true},
+		{"describe unknown", false, true},
+		{"unknown", false, true},
+		{"run", false, true},
+		{"run shell_method {\"x\":", false, true},
+		{"run unknown {\"x\":1}", false, true},
+		{"run shell_method {\"x\": 1}", false, false},
+		{"use", false, true},
+		{"use 1", false, true},
+		{"use x", false, true},
+		{"transfer 0x01", false, true},
+		{"receipt", false, true},
+		{"   ", false, false},
+		{"exit", true, false},
+		{"quit now", true, false},
+	}
+	s := NewShell()
+	for _, v := range testdata {
+		exit, err := s.execute(context.Background(), v.input)
+		if exit != v.exit || (err != nil) != v.err {
+			t.Errorf("input %q expect exit %v error %v, got %v %v", v.input, v.exit, v.err, exit, err)
+		}
+	}
+	if string(params) != `{"x": 1}` {
+		t.Errorf("unexpected params %s", params)
+	}
+}
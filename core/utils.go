@@ -1,11 +1,14 @@
 package core
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"math/big"
-	"time"
+	"sync"
 
 	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
 	"github.com/dylenfu/zion-tool/pkg/log"
 	"github.com/dylenfu/zion-tool/pkg/sdk"
 	"github.com/ethereum/go-ethereum/params"
@@ -40,23 +43,37 @@ func generateStakeAccounts(indexList []int) ([]*Account, error) {
 	return list, nil
 }
 
+// stakeAccounts caches the stake accounts of nodes, so that the methods and
+// instances sending txs from the same account share one nonce manager.
+var (
+	stakeAccountsMu sync.Mutex
+	stakeAccounts   = make(map[*config.Node]*Account)
+)
+
 func generateStakeAccount(index int) (*Account, error) {
 	chainID := config.Conf.ChainID
-	if index >= len(config.Conf.Nodes) {
+	if index < 0 || index >= len(config.Conf.Nodes) {
 		return nil, fmt.Errorf("node index out of range")
 	}
 	node := config.Conf.Nodes[index]
+
+	stakeAccountsMu.Lock()
+	defer stakeAccountsMu.Unlock()
+	if acc, ok := stakeAccounts[node]; ok {
+		return acc, nil
+	}
 	acc, err := sdk.CustomNewAccount(chainID, node.Url, node.StakePrivateKey)
 	if err != nil {
 		return nil, err
 	}
-	return &Account{
+	stakeAccounts[node] = &Account{
 		Node:    node,
 		Account: acc,
-	}, nil
+	}
+	return stakeAccounts[node], nil
 }
 
-func prepareBalance() error {
+func prepareBalance(ctx context.Context) error {
 	amount := new(big.Int).Mul(big.NewInt(int64(config.Conf.InitBalance)), ETH1)
 	master, err := masterAccount()
 	if err != nil {
@@ -66,7 +83,7 @@ func prepareBalance() error {
 	// the first one is master account
 	for i := 0; i < len(config.Conf.Nodes); i++ {
 		addr := config.Conf.Nodes[i].StakeAddr
-		balance, err := master.BalanceOf(addr, nil)
+		balance, err := master.BalanceOfContext(ctx, addr, nil)
 		if err != nil {
 			return err
 		} else {
@@ -76,7 +93,7 @@ func prepareBalance() error {
 			continue
 		}
 		added := new(big.Int).Sub(amount, balance)
-		if _, err := master.Transfer(addr, added); err != nil {
+		if _, err := master.TransferContext(ctx, addr, added); err != nil {
 			return err
 		} else {
 			log.Infof("prepare %v balance %v, added %v", addr.Hex(), amount, added)
@@ -85,6 +102,26 @@ func prepareBalance() error {
 	return nil
 }
 
-func wait() {
-	time.Sleep(config.Conf.BlockWaitingTime())
+// loadParams decodes the inline params of running step, and fallback to the
+// case file in workspace if the step has no params. params loaded from case
+// file are recorded so that `-rerun-failed` replays them.
+func loadParams(ctx context.Context, fileName string, data interface{}) error {
+	if params := frame.StepParams(ctx); len(params) > 0 {
+		return json.Unmarshal(params, data)
+	}
+	if err := config.LoadParams(fileName, data); err != nil {
+		return err
+	}
+	return frame.RecordParams(ctx, data)
+}
+
+// waitBlocks waits until n new blocks produced, so that the state changed
+// by the txs sent is settled.
+func waitBlocks(ctx context.Context, acc *Account, n uint64) error {
+	height, err := acc.WaitBlocks(ctx, n, config.Conf.BlockWaitTimeout(n))
+	if err != nil {
+		return fmt.Errorf("failed to wait %d blocks, err: %w", n, err)
+	}
+	log.Infof("current block height %d", height)
+	return nil
 }
@@ -0,0 +1,56 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/dylenfu/zion-tool/config"
+	"github.com/dylenfu/zion-tool/pkg/frame"
+)
+
+func TestLoadParams(t *testing.T) {
+	dir, err := ioutil.TempDir("", "workspace")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := os.MkdirAll(filepath.Join(dir, "cases"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	caseFile := `{"To":["0x01"],"Amount":1}`
+	if err := ioutil.WriteFile(filepath.Join(dir, "cases", caseTransfer), []byte(caseFile), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	conf := config.Conf
+	defer func() { config.Conf = conf }()
+	config.Conf = &config.Config{Workspace: dir}
+
+	loaded := make(map[string]*TransferParam)
+	frame.Tool.SetInterval(0)
+	frame.Tool.RegHandler("load_params", func(ctx context.Context) *frame.Result {
+		param := new(TransferParam)
+		if err := loadParams(ctx, caseTransfer, param); err != nil {
+			return frame.Fail(err)
+		}
+		loaded[frame.StepFrom(ctx).Name] = param
+		return frame.Succeed()
+	})
+	frame.Tool.StartSteps([]*frame.Step{
+		{Name: "flags", Method: "load_params", Params: json.RawMessage(`{"To":["0x02"],"Amount":2}`)},
+		{Name: "case", Method: "load_params", DependsOn: []string{"flags"}},
+	})
+
+	expect := map[string]*TransferParam{
+		"flags": {To: []string{"0x02"}, Amount: 2},
+		"case":  {To: []string{"0x01"}, Amount: 1},
+	}
+	if !reflect.DeepEqual(loaded, expect) {
+		t.Fatalf("expect params %+v, got %+v", expect, loaded)
+	}
+}
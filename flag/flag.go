@@ -18,6 +18,7 @@
 package flag
 
 import (
+	"github.com/dylenfu/zion-tool/pkg/frame"
 	"github.com/urfave/cli"
 )
 
@@ -30,7 +31,7 @@ var (
 
 	NumberFlag = cli.Uint64Flag{
 		Name:  "num",
-		Usage: "test instance number, e.g: user number in tps/stable testing",
+		Usage: "test instance number of concurrent methods, e.g: user number of transfer and bench in tps/stable testing",
 		Value: 1,
 	}
 
@@ -50,4 +51,122 @@ var (
 		Usage: "gas price increase n wei",
 		Value: 0,
 	}
+
+	LogLevelFlag = cli.IntFlag{
+		Name:  "loglevel",
+		Usage: "loglevel [1: debug, 2: info]",
+		Value: 2,
+	}
+
+	MethodsFlag = cli.StringFlag{
+		Name:  "t",
+		Usage: "methods to run, use ',' to split selectors. e.g: transfer,epoch*,tag:smoke,!tag:epoch",
+		Value: "demo",
+	}
+
+	ScenarioFlag = cli.StringFlag{
+		Name:  "scenario",
+		Usage: "scenario file to run instead of methods list, json or yaml",
+	}
+
+	StateFlag = cli.StringFlag{
+		Name:  "state",
+		Usage: "persist run state shared by methods in workspace, e.g: state.json",
+	}
+
+	WorkersFlag = cli.IntFlag{
+		Name:  "workers",
+		Usage: "max number of methods running concurrently",
+		Value: frame.DefaultWorkers,
+	}
+
+	IntervalFlag = cli.DurationFlag{
+		Name:  "interval",
+		Usage: "rest time after each method finished",
+		Value: frame.DefaultInterval,
+	}
+
+	ReportFlag = cli.StringFlag{
+		Name:  "report",
+		Usage: "write json run report to the `<path>`",
+	}
+
+	JUnitFlag = cli.StringFlag{
+		Name:  "junit",
+		Usage: "write junit xml run report to the `<path>`",
+	}
+
+	CIFlag = cli.BoolFlag{
+		Name:  "ci",
+		Usage: "ci mode, disable colors of logs",
+	}
+
+	FailFastFlag = cli.BoolFlag{
+		Name:  "fail-fast",
+		Usage: "stop running methods at the first failure",
+	}
+
+	RerunFailedFlag = cli.BoolFlag{
+		Name:  "rerun-failed",
+		Usage: "rerun the failed and skipped methods of the last run recorded in workspace",
+	}
+
+	AddrFlag = cli.StringFlag{
+		Name:  "addr",
+		Usage: "listen address of http control server",
+		Value: "127.0.0.1:8080",
+	}
+
+	TokenFlag = cli.StringFlag{
+		Name:   "token",
+		Usage:  "bearer token of http control server, required if not listening on loopback",
+		EnvVar: "ZION_TOOL_TOKEN",
+	}
+
+	// the deprecated flags of commands before subcommands, they still work
+	// without subcommand and dispatch to the commands.
+	ListFlag = cli.BoolFlag{
+		Name:  "list",
+		Usage: "deprecated, use command methods instead",
+	}
+
+	DescribeFlag = cli.StringFlag{
+		Name:  "describe",
+		Usage: "deprecated, use command describe instead",
+	}
+
+	GenCasesFlag = cli.BoolFlag{
+		Name:  "gen-cases",
+		Usage: "deprecated, use command gen-cases instead",
+	}
+
+	ShellFlag = cli.BoolFlag{
+		Name:  "shell",
+		Usage: "deprecated, use command shell instead",
+	}
+
+	ServeFlag = cli.StringFlag{
+		Name:  "serve",
+		Usage: "deprecated, use command serve with --addr instead",
+	}
+
+	ToFlag = cli.StringFlag{
+		Name:  "to",
+		Usage: "receiver addresses, use ',' to split addresses",
+	}
+
+	AmountFlag = cli.Uint64Flag{
+		Name:  "amount",
+		Usage: "amount in ether",
+	}
+
+	NodesFlag = cli.StringFlag{
+		Name:  "nodes",
+		Usage: "index of nodes in config, use ',' to split indexes. e.g: 1,2,3",
+	}
+
+	HeightFlag = cli.Uint64Flag{
+		Name:  "height",
+		Usage: "block height",
+	}
 )
@@ -0,0 +1,43 @@
+package flag
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const Day = 24 * time.Hour
+
+var dayPattern = regexp.MustCompile(`^(\d+)d`)
+
+// ParsePeriod parses positive lasting time with day unit which is not
+// supported by time.ParseDuration, e.g: 1d, 1d2h, 1d2h30m40s, 90m.
+func ParsePeriod(s string) (time.Duration, error) {
+	var (
+		days time.Duration
+		rest = s
+	)
+	if m := dayPattern.FindStringSubmatch(s); m != nil {
+		n, err := strconv.ParseUint(m[1], 10, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid period %s, err: %v", s, err)
+		}
+		days = time.Duration(n) * Day
+		rest = s[len(m[0]):]
+	}
+	var d time.Duration
+	if rest != "" {
+		var err error
+		if d, err = time.ParseDuration(rest); err != nil {
+			return 0, fmt.Errorf("invalid period %s, err: %v", s, err)
+		}
+		if d < 0 {
+			return 0, fmt.Errorf("invalid period %s, negative duration", s)
+		}
+	}
+	if days+d <= 0 {
+		return 0, fmt.Errorf("invalid period %q, should be positive", s)
+	}
+	return days + d, nil
+}
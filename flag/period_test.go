@@ -0,0 +1,35 @@
+package flag
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParsePeriod(t *testing.T) {
+	var testdata = []struct {
+		input  string
+		expect time.Duration
+	}{
+		{"1d", Day},
+		{"1d2h", Day + 2*time.Hour},
+		{"1d2h30m40s", Day + 2*time.Hour + 30*time.Minute + 40*time.Second},
+		{"2h30m", 2*time.Hour + 30*time.Minute},
+		{"10s", 10 * time.Second},
+		{"0d1h", time.Hour},
+	}
+	for _, v := range testdata {
+		d, err := ParsePeriod(v.input)
+		if err != nil {
+			t.Fatalf("parse %s failed, err: %v", v.input, err)
+		}
+		if d != v.expect {
+			t.Errorf("parse %s expect %v, got %v", v.input, v.expect, d)
+		}
+	}
+
+	for _, input := range []string{"", "d", "1x", "1d-2h", "2h1d", "0d", "0s", "0d0h", "-1h"} {
+		if _, err := ParsePeriod(input); err == nil {
+			t.Errorf("expect error for %q", input)
+		}
+	}
+}
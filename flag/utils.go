@@ -1,10 +1,12 @@
 package flag
 
 import (
+	"fmt"
 	"github.com/dylenfu/zion-tool/pkg/math"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/urfave/cli"
 	"math/big"
+	"strconv"
 	"strings"
 	"time"
 )
@@ -45,9 +47,54 @@ func Flag2Duration(ctx *cli.Context, f cli.Flag) (time.Duration, error) {
 	fn := GetFlagName(f)
 	data := ctx.String(fn)
 
-	tmp, err := time.ParseDuration(data)
-	if err != nil {
-		return 0, err
+	return ParsePeriod(data)
+}
+
+func Flag2bool(ctx *cli.Context, f cli.Flag) bool {
+	fn := GetFlagName(f)
+	return ctx.Bool(fn)
+}
+
+func Flag2int(ctx *cli.Context, f cli.Flag) int {
+	fn := GetFlagName(f)
+	return ctx.Int(fn)
+}
+
+func Flag2time(ctx *cli.Context, f cli.Flag) time.Duration {
+	fn := GetFlagName(f)
+	return ctx.Duration(fn)
+}
+
+// Flag2list splits the comma separated flag value, empty items are dropped
+func Flag2list(ctx *cli.Context, f cli.Flag) []string {
+	list := make([]string, 0)
+	for _, item := range strings.Split(Flag2string(ctx, f), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+// Flag2intList parses the comma separated integers, e.g: node indexes
+func Flag2intList(ctx *cli.Context, f cli.Flag) ([]int, error) {
+	list := make([]int, 0)
+	for _, item := range Flag2list(ctx, f) {
+		n, err := strconv.Atoi(item)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s %s, err: %v", GetFlagName(f), item, err)
+		}
+		list = append(list, n)
+	}
+	return list, nil
+}
+
+// IsSet returns true if any of the flags is set in command line
+func IsSet(ctx *cli.Context, flags ...cli.Flag) bool {
+	for _, f := range flags {
+		if ctx.IsSet(GetFlagName(f)) {
+			return true
+		}
 	}
-	return time.Duration(tmp), nil
+	return false
 }
@@ -0,0 +1,70 @@
+package flag
+
+import (
+	goflag "flag"
+	"reflect"
+	"testing"
+
+	"github.com/urfave/cli"
+)
+
+// newTestContext parses args with flags like the command line
+func newTestContext(t *testing.T, flags []cli.Flag, args ...string) *cli.Context {
+	set := goflag.NewFlagSet("test", goflag.ContinueOnError)
+	for _, f := range flags {
+		f.Apply(set)
+	}
+	if err := set.Parse(args); err != nil {
+		t.Fatal(err)
+	}
+	return cli.NewContext(nil, set, nil)
+}
+
+func TestFlag2intList(t *testing.T) {
+	var testdata = []struct {
+		args   []string
+		expect []int
+		err    bool
+	}{
+		{[]string{"--nodes=1,2,3"}, []int{1, 2, 3}, false},
+		{[]string{"--nodes= 1, ,2 "}, []int{1, 2}, false},
+		{[]string{"--nodes="}, []int{}, false},
+		{nil, []int{}, false},
+		{[]string{"--nodes=1,x"}, nil, true},
+		{[]string{"--nodes=1.5"}, nil, true},
+	}
+	for _, v := range testdata {
+		ctx := newTestContext(t, []cli.Flag{NodesFlag}, v.args...)
+		list, err := Flag2intList(ctx, NodesFlag)
+		if (err != nil) != v.err {
+			t.Errorf("args %v expect error %v, got %v", v.args, v.err, err)
+			continue
+		}
+		if !v.err && !reflect.DeepEqual(list, v.expect) {
+			t.Errorf("args %v expect %v, got %v", v.args, v.expect, list)
+		}
+	}
+}
+
+func TestIsSet(t *testing.T) {
+	flags := []cli.Flag{ToFlag, AmountFlag, HeightFlag}
+	var testdata = []struct {
+		args   []string
+		check  []cli.Flag
+		expect bool
+	}{
+		{nil, []cli.Flag{ToFlag, AmountFlag}, false},
+		{[]string{"--amount=1"}, []cli.Flag{ToFlag, AmountFlag}, true},
+		{[]string{"--to=0x01"}, []cli.Flag{ToFlag}, true},
+		// flags set to default values are still set
+		{[]string{"--amount=0"}, []cli.Flag{AmountFlag}, true},
+		{[]string{"--height=1"}, []cli.Flag{ToFlag, AmountFlag}, false},
+		{[]string{"--height=1"}, nil, false},
+	}
+	for _, v := range testdata {
+		ctx := newTestContext(t, flags, v.args...)
+		if got := IsSet(ctx, v.check...); got != v.expect {
+			t.Errorf("args %v expect %v, got %v", v.args, v.expect, got)
+		}
+	}
+}
@@ -3,9 +3,12 @@ module github.com/dylenfu/zion-tool
 go 1.15
 
 require (
+	github.com/dop251/goja v0.0.0-20200721192441-a695b0cdd498
 	github.com/ethereum/go-ethereum v1.10.14
+	github.com/peterh/liner v1.1.1-0.20190123174540-a2c9a5303de7
 	github.com/stretchr/testify v1.7.0
 	github.com/urfave/cli v1.22.4
+	gopkg.in/yaml.v3 v3.0.0-20200313102051-9f266ea9e77c
 )
 
 replace (
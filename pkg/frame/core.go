@@ -1,152 +1,304 @@
 package frame
 
 import (
+	"context"
+	"fmt"
+	"runtime/debug"
 	"time"
 
 	"github.com/dylenfu/zion-tool/pkg/log"
 )
 
-var (
-	Tool      = NewPaletteTool()
-	startTime = time.Now().Unix()
+const (
+	DefaultWorkers  = 1
+	DefaultInterval = 5 * time.Second
 )
 
-type Method func() bool
+var (
+	Tool = NewPaletteTool()
+)
 
 type PaletteTool struct {
 	//Map name to method
-	methodsMap map[string]Method
-	//Map method result
-	methodsRes map[string]bool
+	methodsMap map[string]*methodEntry
+	//method names in registration order
+	names []string
+	//max number of methods running at the same time
+	workers int
+	//rest time after each method finished
+	interval time.Duration
+	//stop dispatching methods after the first failure
+	failFast bool
+	//default method timeout, 0 means no limit
+	timeout time.Duration
+	//Map name to method timeout which overrides the default one
+	timeouts map[string]time.Duration
+	//report of the last run
+	report *RunReport
+	//state shared by methods
+	state *State
+	//listeners notified on run and method events
+	listeners []Listener
+	//id of the current run
+	runID string
+	//path of run record persisted after each run
+	recordPath string
+	//default number of concurrent instances of concurrent methods
+	instances int
+	//hook preparing every instance
+	instanceSetup InstanceSetup
 }
 
 func NewPaletteTool() *PaletteTool {
 	return &PaletteTool{
-		methodsMap: make(map[string]Method, 0),
-		methodsRes: make(map[string]bool, 0),
+		methodsMap: make(map[string]*methodEntry, 0),
+		timeouts:   make(map[string]time.Duration, 0),
+		state:      NewState(),
+		listeners:  []Listener{logListener{}},
+		workers:    DefaultWorkers,
+		interval:   DefaultInterval,
+		instances:  1,
+	}
+}
+
+// RegMethod register legacy method which only returns success or failure
+func (pt *PaletteTool) RegMethod(name string, method Method, opts ...Option) {
+	pt.RegHandler(name, Adapt(method), opts...)
+}
+
+// RegHandler register context-aware method
+func (pt *PaletteTool) RegHandler(name string, handler Handler, opts ...Option) {
+	entry := &methodEntry{name: name, handler: handler}
+	for _, opt := range opts {
+		opt(entry)
+	}
+	if _, exist := pt.methodsMap[name]; !exist {
+		pt.names = append(pt.names, name)
+	}
+	pt.methodsMap[name] = entry
+}
+
+// SetWorkers set the max number of methods running concurrently, methods
+// without dependency relationship will be executed in parallel.
+func (pt *PaletteTool) SetWorkers(n int) {
+	if n < 1 {
+		n = 1
+	}
+	pt.workers = n
+}
+
+// SetInterval set the rest time after each method finished
+func (pt *PaletteTool) SetInterval(d time.Duration) {
+	if d < 0 {
+		d = 0
 	}
+	pt.interval = d
 }
 
-func (pt *PaletteTool) RegMethod(name string, method Method) {
-	pt.methodsMap[name] = method
+// SetFailFast stops the run at the first failed method, methods not started
+// yet are skipped and running ones are waited to finish.
+func (pt *PaletteTool) SetFailFast(failFast bool) {
+	pt.failFast = failFast
+}
+
+// SetTimeout set the default timeout of every method, 0 means no limit
+func (pt *PaletteTool) SetTimeout(d time.Duration) {
+	pt.timeout = d
+}
+
+// SetMethodTimeout overrides the default timeout for the named method
+func (pt *PaletteTool) SetMethodTimeout(name string, d time.Duration) {
+	pt.timeouts[name] = d
+}
+
+func (pt *PaletteTool) timeoutOf(name string) time.Duration {
+	if d, ok := pt.timeouts[name]; ok {
+		return d
+	}
+	return pt.timeout
+}
+
+// State returns the state shared by methods
+func (pt *PaletteTool) State() *State {
+	return pt.state
+}
+
+// SetStatePath loads the state persisted by previous run, and state will be
+// saved to the path after every method finished.
+func (pt *PaletteTool) SetStatePath(path string) error {
+	state, err := LoadState(path)
+	if err != nil {
+		return err
+	}
+	pt.state = state
+	return nil
 }
 
 //Start run
 func (pt *PaletteTool) Start(methodsList []string) {
-	if len(methodsList) > 0 {
-		pt.runMethodList(methodsList)
+	pt.StartSteps(NewSteps(methodsList))
+}
+
+// StartSteps run steps, e.g: loaded from scenario file
+func (pt *PaletteTool) StartSteps(steps []*Step) {
+	if len(steps) > 0 {
+		pt.runSteps(steps)
 		return
 	}
 	log.Info("No method to run")
 	return
 }
 
-func (pt *PaletteTool) runMethodList(methodsList []string) {
-	pt.onStart()
-	defer pt.onFinish(methodsList)
+func (pt *PaletteTool) runSteps(steps []*Step) {
+	tasks := pt.buildTasks(steps)
 
-	var rest = func(index int) {
-		n := len(methodsList)
-		if n > 1 && index < n-1 {
-			time.Sleep(5 * time.Second)
-		}
-	}
+	start := time.Now()
+	pt.onStart(len(tasks))
 
-	for i, method := range methodsList {
-		pt.runMethod(i+1, method)
-		rest(i)
-	}
+	pt.schedule(withState(context.Background(), pt.state), tasks)
+	end := time.Now()
+	pt.report = newRunReport(start, end, tasks)
+	pt.saveRecord(start, end, tasks)
+	pt.onFinish(pt.report)
 }
 
-func (pt *PaletteTool) runMethod(index int, methodName string) {
-	pt.onBeforeMethodStart(index, methodName)
-	method := pt.getMethodByName(methodName)
-	if method != nil {
-		ok := method()
-		pt.onAfterMethodFinish(index, methodName, ok)
-		pt.methodsRes[methodName] = ok
-	}
+// Report returns the report of the last run, it's nil if nothing ran.
+func (pt *PaletteTool) Report() *RunReport {
+	return pt.report
 }
 
-func (pt *PaletteTool) onStart() {
-	log.Info("===============================================================")
-	log.Info("-------Zion Tool Start-------")
-	log.Info("===============================================================")
-	log.Info("")
+func (pt *PaletteTool) runMethod(ctx context.Context, t *task) {
+	t.start = time.Now()
+	pt.onBeforeMethodStart(t)
+	sc := &stepContext{step: t.step}
+	var res *Result
+	if n := pt.instancesOf(t.step, t.entry); n > 1 {
+		res = pt.callInstances(withStep(ctx, sc), t.step.Method, t.entry, n)
+	} else {
+		res = pt.callWithRetry(withStep(ctx, sc), t.step.Method, t.entry)
+	}
+	t.end = time.Now()
+	if res.Duration == 0 {
+		res.Duration = t.end.Sub(t.start)
+	}
+	t.res = res
+	t.params = sc.usedParams()
+	pt.onAfterMethodFinish(t)
 }
 
-func (pt *PaletteTool) onFinish(methodsList []string) {
-	failedList := make([]string, 0)
-	successList := make([]string, 0)
-	for methodName, ok := range pt.methodsRes {
-		if ok {
-			successList = append(successList, methodName)
-		} else {
-			failedList = append(failedList, methodName)
+// callWithRetry executes method until it succeed or the retry policy gives up,
+// the timeout is applied to every attempt.
+func (pt *PaletteTool) callWithRetry(ctx context.Context, name string, entry *methodEntry) *Result {
+	for attempt := 1; ; attempt++ {
+		res := pt.call(ctx, name, entry.handler)
+		res.Attempts = attempt
+		if !entry.retry.shouldRetry(attempt, res.Err) {
+			return res
 		}
-	}
 
-	skipList := make([]string, 0)
-	for _, method := range methodsList {
-		_, ok := pt.methodsRes[method]
-		if !ok {
-			skipList = append(skipList, method)
+		delay := entry.retry.delay(attempt)
+		log.Warnf("Run Method:%s attempt %d failed, retry after %v, err: %v", name, attempt, delay, res.Err)
+		select {
+		case <-ctx.Done():
+			return res
+		case <-time.After(delay):
 		}
 	}
+}
 
-	succCount := len(successList)
-	failedCount := len(failedList)
-	endTime := time.Now().Unix()
-
-	log.Info("===============================================================")
-	log.Infof("Zion Tool Finish Total:%v Success:%v Failed:%v Skip:%v, SpendTime:%d sec",
-		len(methodsList),
-		succCount,
-		failedCount,
-		len(methodsList)-succCount-failedCount,
-		endTime-startTime,
-	)
-
-	if succCount > 0 {
-		log.Info("---------------------------------------------------------------")
-		log.Info("Success list:")
-		for i, succ := range successList {
-			log.Infof("%d.\t%s", i+1, succ)
-		}
+// call executes handler with the method timeout. the handler receives a
+// context which is cancelled on timeout, and the method is marked as failed
+// immediately even if the handler does not return in time.
+func (pt *PaletteTool) call(ctx context.Context, name string, handler Handler) *Result {
+	timeout := pt.timeoutOf(name)
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
 	}
-	if failedCount > 0 {
-		log.Info("---------------------------------------------------------------")
-		log.Info("Fail list:")
-		for i, fail := range failedList {
-			log.Infof("%d.\t%s", i+1, fail)
+
+	done := make(chan *Result, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				stack := string(debug.Stack())
+				res := Fail(&PanicError{Value: r, Stack: stack})
+				res.Stack = stack
+				done <- res
+			}
+		}()
+		done <- handler(ctx)
+	}()
+
+	var res *Result
+	select {
+	case res = <-done:
+		if res == nil {
+			res = Succeed()
 		}
-	}
-	if len(skipList) > 0 {
-		log.Info("---------------------------------------------------------------")
-		log.Info("Skip list:")
-		for i, skip := range skipList {
-			log.Infof("%d.\t%s", i+1, skip)
+		if res.Err != nil && ctx.Err() == context.DeadlineExceeded {
+			res.Err = fmt.Errorf("%w after %v: %v", ErrTimeout, timeout, res.Err)
+		}
+	case <-ctx.Done():
+		if ctx.Err() == context.DeadlineExceeded {
+			res = Fail(fmt.Errorf("%w after %v", ErrTimeout, timeout))
+		} else {
+			res = Fail(ctx.Err())
 		}
 	}
-	log.Info("===============================================================")
+	return res
 }
 
-func (pt *PaletteTool) onBeforeMethodStart(index int, methodName string) {
-	log.Info("===============================================================")
-	log.Infof("%d. Start Method:%s", index, methodName)
-	log.Info("---------------------------------------------------------------")
+// AddListener registers listener notified on run and method events, listeners
+// are called in registration order after the built-in logger.
+func (pt *PaletteTool) AddListener(l Listener) {
+	pt.listeners = append(pt.listeners, l)
 }
 
-func (pt *PaletteTool) onAfterMethodFinish(index int, methodName string, res bool) {
-	if res {
-		log.Infof("Run Method:%s success.", methodName)
-	} else {
-		log.Infof("Run Method:%s failed.", methodName)
+func (pt *PaletteTool) onStart(total int) {
+	pt.runID = newRunID()
+	ev := pt.newEvent(EventRunStart)
+	ev.Total = total
+	for _, l := range pt.listeners {
+		l.OnStart(ev)
+	}
+}
+
+func (pt *PaletteTool) onFinish(report *RunReport) {
+	ev := pt.newEvent(EventRunFinish)
+	ev.Total = report.Total
+	ev.Report = report
+	for _, l := range pt.listeners {
+		l.OnFinish(ev)
+	}
+}
+
+func (pt *PaletteTool) onBeforeMethodStart(t *task) {
+	ev := pt.newEvent(EventMethodStart)
+	ev.Method = newMethodReport(t, StatusPending)
+	for _, l := range pt.listeners {
+		l.OnMethodStart(ev)
+	}
+}
+
+// onAfterMethodFinish is called in worker, the task status is not set yet so
+// that it's derived from the result.
+func (pt *PaletteTool) onAfterMethodFinish(t *task) {
+	status := StatusSuccess
+	if !t.res.Succeed() {
+		status = StatusFailed
 	}
-	log.Info("---------------------------------------------------------------")
-	log.Info("")
+	ev := pt.newEvent(EventMethodFinish)
+	ev.Method = newMethodReport(t, status)
+	for _, l := range pt.listeners {
+		l.OnMethodFinish(ev)
+	}
+}
+
+func (pt *PaletteTool) newEvent(typ EventType) *Event {
+	return &Event{Type: typ, RunID: pt.runID, Time: time.Now()}
 }
 
-func (pt *PaletteTool) getMethodByName(name string) Method {
+func (pt *PaletteTool) getMethodByName(name string) *methodEntry {
 	return pt.methodsMap[name]
 }
@@ -0,0 +1,300 @@
+package frame
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func newTestTool() *PaletteTool {
+	pt := NewPaletteTool()
+	pt.SetInterval(0)
+	return pt
+}
+
+func statusOf(tasks []*task) map[string]Status {
+	m := make(map[string]Status)
+	for _, t := range tasks {
+		m[t.name] = t.status
+	}
+	return m
+}
+
+func TestScheduleSequentialOrder(t *testing.T) {
+	pt := newTestTool()
+
+	var (
+		mu    sync.Mutex
+		order []string
+	)
+	record := func(name string) Handler {
+		return func(ctx context.Context) *Result {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return Succeed()
+		}
+	}
+	pt.RegHandler("a", record("a"))
+	pt.RegHandler("b", record("b"), DependsOn("c"))
+	pt.RegHandler("c", record("c"))
+
+	tasks := pt.buildTasks(NewSteps([]string{"a", "b", "c"}))
+	pt.schedule(context.Background(), tasks)
+
+	expect := []string{"a", "c", "b"}
+	if len(order) != len(expect) {
+		t.Fatalf("expect %v, got %v", expect, order)
+	}
+	for i := range expect {
+		if order[i] != expect[i] {
+			t.Fatalf("expect %v, got %v", expect, order)
+		}
+	}
+}
+
+func TestScheduleSkipDependents(t *testing.T) {
+	pt := newTestTool()
+	pt.RegHandler("register", func(ctx context.Context) *Result {
+		return Fail(errors.New("register failed"))
+	})
+	pt.RegMethod("stake", func() bool { return true }, DependsOn("register"))
+	pt.RegMethod("withdraw", func() bool { return true }, DependsOn("stake"))
+	pt.RegMethod("transfer", func() bool { return true })
+
+	tasks := pt.buildTasks(NewSteps([]string{"register", "stake", "withdraw", "transfer", "unknown"}))
+	pt.schedule(context.Background(), tasks)
+
+	expect := map[string]Status{
+		"register": StatusFailed,
+		"stake":    StatusSkipped,
+		"withdraw": StatusSkipped,
+		"transfer": StatusSuccess,
+		"unknown":  StatusSkipped,
+	}
+	for name, status := range statusOf(tasks) {
+		if expect[name] != status {
+			t.Errorf("method %s expect %s, got %s", name, expect[name], status)
+		}
+	}
+}
+
+func TestScheduleCycle(t *testing.T) {
+	pt := newTestTool()
+	pt.RegMethod("a", func() bool { return true }, DependsOn("b"))
+	pt.RegMethod("b", func() bool { return true }, DependsOn("a"))
+	pt.RegMethod("c", func() bool { return true })
+
+	tasks := pt.buildTasks(NewSteps([]string{"a", "b", "c"}))
+	pt.schedule(context.Background(), tasks)
+
+	status := statusOf(tasks)
+	if status["a"] != StatusSkipped || status["b"] != StatusSkipped || status["c"] != StatusSuccess {
+		t.Fatalf("unexpected status %v", status)
+	}
+}
+
+func TestScheduleParallel(t *testing.T) {
+	pt := newTestTool()
+	pt.SetWorkers(3)
+
+	var running, peak int32
+	handler := func(ctx context.Context) *Result {
+		n := atomic.AddInt32(&running, 1)
+		for {
+			old := atomic.LoadInt32(&peak)
+			if n <= old || atomic.CompareAndSwapInt32(&peak, old, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+		atomic.AddInt32(&running, -1)
+		return Succeed()
+	}
+	for _, name := range []string{"a", "b", "c", "d", "e", "f"} {
+		pt.RegHandler(name, handler)
+	}
+
+	tasks := pt.buildTasks(NewSteps([]string{"a", "b", "c", "d", "e", "f"}))
+	pt.schedule(context.Background(), tasks)
+
+	if peak != 3 {
+		t.Fatalf("expect 3 methods running concurrently, got %d", peak)
+	}
+	for name, status := range statusOf(tasks) {
+		if status != StatusSuccess {
+			t.Errorf("method %s expect success, got %s", name, status)
+		}
+	}
+}
+
+func TestMethodTimeout(t *testing.T) {
+	pt := newTestTool()
+	pt.SetTimeout(time.Second)
+	pt.SetMethodTimeout("hang", 50*time.Millisecond)
+
+	cancelled := make(chan struct{})
+	pt.RegHandler("hang", func(ctx context.Context) *Result {
+		<-ctx.Done()
+		close(cancelled)
+		time.Sleep(time.Second)
+		return Fail(ctx.Err())
+	})
+	pt.RegMethod("quick", func() bool { return true })
+
+	tasks := pt.buildTasks(NewSteps([]string{"hang", "quick"}))
+	pt.schedule(context.Background(), tasks)
+
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Fatal("method context not cancelled")
+	}
+	if tasks[0].status != StatusFailed || !errors.Is(tasks[0].res.Err, ErrTimeout) {
+		t.Fatalf("expect timed out, got %s %v", tasks[0].status, tasks[0].res.Err)
+	}
+	if tasks[0].res.Duration >= time.Second {
+		t.Fatalf("method should be aborted at timeout, spend %v", tasks[0].res.Duration)
+	}
+	if tasks[1].status != StatusSuccess {
+		t.Fatalf("expect quick method success, got %s", tasks[1].status)
+	}
+}
+
+func TestStartSteps(t *testing.T) {
+	pt := newTestTool()
+
+	received := make(map[string]string)
+	pt.RegHandler("transfer", func(ctx context.Context) *Result {
+		var param struct{ To string }
+		if err := json.Unmarshal(StepParams(ctx), &param); err != nil {
+			return Fail(err)
+		}
+		received[StepFrom(ctx).Name] = param.To
+		return Succeed()
+	})
+
+	pt.StartSteps([]*Step{
+		{Name: "second", Method: "transfer", Params: json.RawMessage(`{"To":"0x02"}`), DependsOn: []string{"first"}},
+		{Name: "first", Method: "transfer", Params: json.RawMessage(`{"To":"0x01"}`)},
+		{Method: "unknown"},
+	})
+
+	report := pt.Report()
+	if report.Success != 2 || report.Skipped != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if received["first"] != "0x01" || received["second"] != "0x02" {
+		t.Fatalf("unexpected params %v", received)
+	}
+	if report.Methods[0].Start.Before(*report.Methods[1].End) {
+		t.Fatalf("step second should start after first finished")
+	}
+	if report.Methods[2].Name != "unknown" {
+		t.Fatalf("step name should default to method name")
+	}
+}
+
+func TestSoak(t *testing.T) {
+	pt := newTestTool()
+
+	count := 0
+	pt.RegHandler("transfer", func(ctx context.Context) *Result {
+		count++
+		time.Sleep(10 * time.Millisecond)
+		if count%2 == 0 {
+			return Fail(errors.New("even"))
+		}
+		return Succeed()
+	})
+	pt.RegMethod("stake", func() bool { return true }, DependsOn("transfer"))
+
+	pt.Soak(NewSteps([]string{"transfer", "stake"}), 100*time.Millisecond)
+
+	report := pt.Report()
+	if report.Iterations < 2 || report.Total != report.Iterations*2 {
+		t.Fatalf("unexpected iterations %d total %d", report.Iterations, report.Total)
+	}
+	transfer, stake := report.Soak[0], report.Soak[1]
+	if transfer.Runs != report.Iterations || transfer.Success+transfer.Failed != transfer.Runs {
+		t.Fatalf("unexpected transfer stats %+v", transfer)
+	}
+	if stake.Success != transfer.Success || stake.Skipped != transfer.Failed {
+		t.Fatalf("unexpected stake stats %+v", stake)
+	}
+	if transfer.Min <= 0 || transfer.Min > transfer.Avg || transfer.Avg > transfer.Max || transfer.P95 > transfer.Max {
+		t.Fatalf("unexpected latency %+v", transfer)
+	}
+}
+
+func TestMethodPanic(t *testing.T) {
+	pt := newTestTool()
+	pt.RegMethod("panic", func() bool {
+		var m map[string]int
+		m["x"] = 1
+		return true
+	})
+	pt.RegMethod("next", func() bool { return true })
+
+	pt.Start([]string{"panic", "next"})
+
+	report := pt.Report()
+	if report.Methods[0].Status != StatusFailed || report.Methods[1].Status != StatusSuccess {
+		t.Fatalf("unexpected report %+v", report.Methods)
+	}
+	if !strings.Contains(report.Methods[0].Message, "panic: assignment to entry in nil map") {
+		t.Fatalf("unexpected message %s", report.Methods[0].Message)
+	}
+	if !strings.Contains(report.Methods[0].Stack, "TestMethodPanic") {
+		t.Fatalf("stack trace not captured: %s", report.Methods[0].Stack)
+	}
+}
+
+func TestFailFast(t *testing.T) {
+	pt := newTestTool()
+	pt.SetFailFast(true)
+	pt.RegMethod("a", func() bool { return true })
+	pt.RegMethod("b", func() bool { return false })
+	pt.RegMethod("c", func() bool { return true })
+
+	pt.Start([]string{"a", "b", "c"})
+
+	report := pt.Report()
+	if report.Success != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	if report.Methods[2].Message != "fail fast: b failed" {
+		t.Fatalf("unexpected skip reason %s", report.Methods[2].Message)
+	}
+}
+
+func TestScheduleInterval(t *testing.T) {
+	interval := 50 * time.Millisecond
+	pt := newTestTool()
+	pt.SetInterval(interval)
+	pt.RegMethod("transfer", func() bool { return true })
+
+	start := time.Now()
+	pt.StartSteps([]*Step{
+		{Name: "first", Method: "transfer"},
+		{Name: "second", Method: "transfer"},
+		{Name: "third", Method: "transfer"},
+	})
+	elapsed := time.Since(start)
+
+	report := pt.Report()
+	for i := 1; i < len(report.Methods); i++ {
+		if gap := report.Methods[i].Start.Sub(*report.Methods[i-1].End); gap < interval {
+			t.Errorf("method %s started %v after the previous one finished", report.Methods[i].Name, gap)
+		}
+	}
+	// no rest after the last method
+	if elapsed >= 3*interval {
+		t.Errorf("expect run finished in %v, got %v", 3*interval, elapsed)
+	}
+}
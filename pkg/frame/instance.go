@@ -0,0 +1,189 @@
+package frame
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Instance identifies one of the concurrent instances of a method
+type Instance struct {
+	// Index starts from 1
+	Index int
+	Total int
+}
+
+// InstanceSetup prepares the resources of instance before the method runs,
+// e.g: ephemeral account. the returned context is passed to the method.
+type InstanceSetup func(ctx context.Context, inst *Instance) (context.Context, error)
+
+// InstanceReport is the outcome of a single instance in method report
+type InstanceReport struct {
+	Index    int
+	Status   Status
+	Duration float64  // seconds
+	Message  string   `json:",omitempty"`
+	Attempts int      `json:",omitempty"`
+	Stack    string   `json:",omitempty"`
+	TxHashes []string `json:",omitempty"`
+}
+
+type instanceKey struct{}
+
+func withInstance(ctx context.Context, inst *Instance) context.Context {
+	return context.WithValue(ctx, instanceKey{}, inst)
+}
+
+// InstanceFrom returns the instance running in the context, it's nil if the
+// method runs as a single instance.
+func InstanceFrom(ctx context.Context) *Instance {
+	inst, _ := ctx.Value(instanceKey{}).(*Instance)
+	return inst
+}
+
+// Concurrent declares the method could run as concurrent instances, e.g: the
+// one sending txs from the instance account instead of node accounts.
+func Concurrent() Option {
+	return func(entry *methodEntry) {
+		entry.info.Concurrent = true
+	}
+}
+
+// SetInstances set the default number of concurrent instances of the steps
+// whose methods are registered with Concurrent, steps with Instances
+// specified are not affected.
+func (pt *PaletteTool) SetInstances(n int) {
+	if n < 1 {
+		n = 1
+	}
+	pt.instances = n
+}
+
+// SetInstanceSetup set the hook preparing every instance
+func (pt *PaletteTool) SetInstanceSetup(setup InstanceSetup) {
+	pt.instanceSetup = setup
+}
+
+func (pt *PaletteTool) instancesOf(step *Step, entry *methodEntry) int {
+	if step.Instances > 0 {
+		return step.Instances
+	}
+	if entry.info.Concurrent {
+		return pt.instances
+	}
+	return 1
+}
+
+// callInstances runs n instances of method concurrently, each instance is
+// retried independently. the merged result fails if any instance failed.
+func (pt *PaletteTool) callInstances(ctx context.Context, name string, entry *methodEntry, n int) *Result {
+	var (
+		wg      sync.WaitGroup
+		results = make([]*Result, n)
+	)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			start := time.Now()
+			inst := &Instance{Index: i + 1, Total: n}
+			ictx := withInstance(ctx, inst)
+			if pt.instanceSetup != nil {
+				var err error
+				if ictx, err = pt.setupInstance(ictx, name, inst); err != nil {
+					results[i] = Fail(err)
+					results[i].Duration = time.Since(start)
+					return
+				}
+			}
+			res := pt.callWithRetry(ictx, name, entry)
+			if res.Duration == 0 {
+				res.Duration = time.Since(start)
+			}
+			results[i] = res
+		}(i)
+	}
+	wg.Wait()
+	return mergeInstances(results)
+}
+
+// setupInstance runs the setup hook with the method timeout, the returned
+// context keeps the values set by setup after the timeout released.
+func (pt *PaletteTool) setupInstance(ctx context.Context, name string, inst *Instance) (context.Context, error) {
+	var values context.Context
+	res := pt.call(ctx, name, func(ctx context.Context) *Result {
+		sctx, err := pt.instanceSetup(ctx, inst)
+		if err != nil {
+			return Fail(err)
+		}
+		values = sctx
+		return Succeed()
+	})
+	if res.Err != nil {
+		return ctx, fmt.Errorf("failed to setup instance, err: %w", res.Err)
+	}
+	return &valuesContext{Context: ctx, values: values}, nil
+}
+
+// valuesContext is cancelled with the embedded context, and looks up values
+// in the context returned by instance setup.
+type valuesContext struct {
+	context.Context
+	values context.Context
+}
+
+func (c *valuesContext) Value(key interface{}) interface{} {
+	return c.values.Value(key)
+}
+
+func mergeInstances(results []*Result) *Result {
+	var (
+		res      = NewResult()
+		failed   = 0
+		firstErr error
+	)
+	res.Instances = results
+	for i, r := range results {
+		res.TxHashes = append(res.TxHashes, r.TxHashes...)
+		if r.Attempts > res.Attempts {
+			res.Attempts = r.Attempts
+		}
+		if r.Stack != "" && res.Stack == "" {
+			res.Stack = r.Stack
+		}
+		if r.Err != nil {
+			failed++
+			if firstErr == nil {
+				firstErr = fmt.Errorf("instance %d: %w", i+1, r.Err)
+			}
+		}
+	}
+	res.SetMetric("instances", len(results))
+	res.SetMetric("instances_failed", failed)
+	if failed > 0 {
+		res.Err = fmt.Errorf("%d of %d instances failed, %w", failed, len(results), firstErr)
+	}
+	return res
+}
+
+func newInstanceReports(results []*Result) []*InstanceReport {
+	list := make([]*InstanceReport, 0, len(results))
+	for i, r := range results {
+		ir := &InstanceReport{
+			Index:    i + 1,
+			Status:   StatusSuccess,
+			Duration: r.Duration.Seconds(),
+			Attempts: r.Attempts,
+			Stack:    r.Stack,
+			TxHashes: r.TxHashes,
+		}
+		if r.Err != nil {
+			ir.Status = StatusFailed
+			ir.Message = r.Err.Error()
+		}
+		list = append(list, ir)
+	}
+	return list
+}
@@ -0,0 +1,118 @@
+package frame
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type testAccountKey struct{}
+
+func TestInstances(t *testing.T) {
+	var (
+		running int32
+		maxRun  int32
+	)
+	pt := newTestTool()
+	pt.SetInstanceSetup(func(ctx context.Context, inst *Instance) (context.Context, error) {
+		if inst.Index == 3 {
+			return ctx, errors.New("no balance")
+		}
+		return context.WithValue(ctx, testAccountKey{}, inst.Index*10), nil
+	})
+	pt.RegHandler("stake", func(ctx context.Context) *Result {
+		n := atomic.AddInt32(&running, 1)
+		defer atomic.AddInt32(&running, -1)
+		for {
+			old := atomic.LoadInt32(&maxRun)
+			if n <= old || atomic.CompareAndSwapInt32(&maxRun, old, n) {
+				break
+			}
+		}
+		time.Sleep(50 * time.Millisecond)
+
+		inst := InstanceFrom(ctx)
+		if inst == nil || inst.Total != 4 || ctx.Value(testAccountKey{}) != inst.Index*10 {
+			return Failf("unexpected instance %+v", inst)
+		}
+		return Succeed().AddTx(fmt.Sprintf("0x%02d", inst.Index))
+	})
+	pt.RegMethod("demo", func() bool { return true }, DependsOn("stake"))
+	pt.StartSteps([]*Step{{Method: "stake", Instances: 4}, {Method: "demo"}})
+
+	if maxRun != 3 {
+		t.Fatalf("expect 3 instances running concurrently, got %d", maxRun)
+	}
+	report := pt.Report()
+	m := report.Methods[0]
+	if m.Status != StatusFailed || len(m.Instances) != 4 || len(m.TxHashes) != 3 {
+		t.Fatalf("unexpected method report %+v", m)
+	}
+	if m.Instances[2].Status != StatusFailed || m.Instances[3].Status != StatusSuccess {
+		t.Fatalf("unexpected instance reports %+v %+v", m.Instances[2], m.Instances[3])
+	}
+	if m.Metrics["instances_failed"] != 1 || report.Methods[1].Status != StatusSkipped {
+		t.Fatalf("unexpected report %+v", report.Methods)
+	}
+}
+
+func TestConcurrentMethods(t *testing.T) {
+	var counts sync.Map
+	count := func(name string) Handler {
+		return func(ctx context.Context) *Result {
+			n, _ := counts.LoadOrStore(name, new(int32))
+			atomic.AddInt32(n.(*int32), 1)
+			return Succeed()
+		}
+	}
+	pt := newTestTool()
+	pt.SetInstances(3)
+	pt.RegHandler("transfer", count("transfer"), Concurrent())
+	pt.RegHandler("register", count("register"))
+	pt.RegHandler("stake", count("stake"))
+	pt.StartSteps([]*Step{{Method: "transfer"}, {Method: "register"}, {Method: "stake", Instances: 2}})
+
+	expect := map[string]int32{"transfer": 3, "register": 1, "stake": 2}
+	for name, n := range expect {
+		v, _ := counts.Load(name)
+		if v == nil || atomic.LoadInt32(v.(*int32)) != n {
+			t.Errorf("expect method %s called %d times, got %v", name, n, v)
+		}
+	}
+	if info, _ := pt.Describe("transfer"); !info.Concurrent {
+		t.Errorf("expect transfer concurrent")
+	}
+}
+
+func TestInstanceSetupTimeout(t *testing.T) {
+	pt := newTestTool()
+	pt.SetTimeout(50 * time.Millisecond)
+	pt.SetInstanceSetup(func(ctx context.Context, inst *Instance) (context.Context, error) {
+		if inst.Index == 1 {
+			<-ctx.Done()
+			return ctx, ctx.Err()
+		}
+		return context.WithValue(ctx, testAccountKey{}, inst.Index), nil
+	})
+	pt.RegHandler("transfer", func(ctx context.Context) *Result {
+		// the value set by setup survives the setup timeout released
+		if ctx.Value(testAccountKey{}) != 2 || ctx.Err() != nil {
+			return Failf("unexpected instance context")
+		}
+		return Succeed()
+	})
+	pt.StartSteps([]*Step{{Method: "transfer", Instances: 2}})
+
+	m := pt.Report().Methods[0]
+	if len(m.Instances) != 2 || m.Instances[1].Status != StatusSuccess {
+		t.Fatalf("unexpected instance reports %+v", m.Instances)
+	}
+	if m.Instances[0].Status != StatusFailed || !strings.Contains(m.Instances[0].Message, ErrTimeout.Error()) {
+		t.Fatalf("expect setup timeout, got %+v", m.Instances[0])
+	}
+}
@@ -0,0 +1,169 @@
+package frame
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+type EventType string
+
+const (
+	EventRunStart     EventType = "run_start"
+	EventMethodStart  EventType = "method_start"
+	EventMethodFinish EventType = "method_finish"
+	EventRunFinish    EventType = "run_finish"
+)
+
+// Event describes the lifecycle event of a run, Method is set for method
+// events and Report is set when the run finished. Total is the number of
+// methods in the run, it's absent from the start event of soak run.
+type Event struct {
+	Type   EventType
+	RunID  string
+	Time   time.Time
+	Total  int           `json:",omitempty"`
+	Method *MethodReport `json:",omitempty"`
+	Report *RunReport    `json:",omitempty"`
+}
+
+// Listener is notified on run and method lifecycle, method events are
+// delivered from workers so that listeners should be safe for concurrent use.
+type Listener interface {
+	OnStart(ev *Event)
+	OnMethodStart(ev *Event)
+	OnMethodFinish(ev *Event)
+	OnFinish(ev *Event)
+}
+
+// BaseListener implements Listener with no-op, embed it to handle part of events.
+type BaseListener struct{}
+
+func (BaseListener) OnStart(ev *Event)        {}
+func (BaseListener) OnMethodStart(ev *Event)  {}
+func (BaseListener) OnMethodFinish(ev *Event) {}
+func (BaseListener) OnFinish(ev *Event)       {}
+
+// ListenerFunc adapts function to Listener which receives all events
+type ListenerFunc func(ev *Event)
+
+func (f ListenerFunc) OnStart(ev *Event)        { f(ev) }
+func (f ListenerFunc) OnMethodStart(ev *Event)  { f(ev) }
+func (f ListenerFunc) OnMethodFinish(ev *Event) { f(ev) }
+func (f ListenerFunc) OnFinish(ev *Event)       { f(ev) }
+
+func newRunID() string {
+	buf := make([]byte, 4)
+	if _, err := rand.Read(buf); err != nil {
+		return fmt.Sprintf("%d", time.Now().UnixNano())
+	}
+	return time.Now().Format("20060102150405") + "-" + hex.EncodeToString(buf)
+}
+
+// logListener is the built-in listener which logs progress and summary
+type logListener struct{}
+
+func (logListener) OnStart(ev *Event) {
+	log.Info("===============================================================")
+	log.Info("-------Zion Tool Start-------")
+	log.Info("===============================================================")
+	log.Info("")
+}
+
+func (logListener) OnMethodStart(ev *Event) {
+	log.Info("===============================================================")
+	log.Infof("%d. Start Method:%s", ev.Method.Index, ev.Method.Name)
+	log.Info("---------------------------------------------------------------")
+}
+
+func (logListener) OnMethodFinish(ev *Event) {
+	m := ev.Method
+	spend := time.Duration(m.Duration * float64(time.Second))
+	if m.Status == StatusSuccess {
+		log.Infof("Run Method:%s success, spend %v.", m.Name, spend)
+	} else {
+		log.Infof("Run Method:%s failed, spend %v, err: %s", m.Name, spend, m.Message)
+	}
+	if m.Stack != "" {
+		log.Errorf("Run Method:%s panic stack:\n%s", m.Name, m.Stack)
+	}
+	log.Info("---------------------------------------------------------------")
+	log.Info("")
+}
+
+func (l logListener) OnFinish(ev *Event) {
+	if ev.Report.Iterations > 0 {
+		l.soakSummary(ev.Report)
+		return
+	}
+	l.summary(ev.Report)
+}
+
+func (logListener) summary(report *RunReport) {
+	failedList := make([]*MethodReport, 0)
+	successList := make([]*MethodReport, 0)
+	skipList := make([]*MethodReport, 0)
+	for _, m := range report.Methods {
+		switch m.Status {
+		case StatusSuccess:
+			successList = append(successList, m)
+		case StatusFailed:
+			failedList = append(failedList, m)
+		default:
+			skipList = append(skipList, m)
+		}
+	}
+
+	log.Info("===============================================================")
+	log.Infof("Zion Tool Finish Total:%v Success:%v Failed:%v Skip:%v, SpendTime:%.0f sec",
+		report.Total,
+		report.Success,
+		report.Failed,
+		report.Skipped,
+		report.Duration,
+	)
+
+	if len(successList) > 0 {
+		log.Info("---------------------------------------------------------------")
+		log.Info("Success list:")
+		for i, succ := range successList {
+			log.Infof("%d.\t%s", i+1, succ.Name)
+		}
+	}
+	if len(failedList) > 0 {
+		log.Info("---------------------------------------------------------------")
+		log.Info("Fail list:")
+		for i, fail := range failedList {
+			log.Infof("%d.\t%s: attempts %d, %s", i+1, fail.Name, fail.Attempts, fail.Message)
+		}
+	}
+	if len(skipList) > 0 {
+		log.Info("---------------------------------------------------------------")
+		log.Info("Skip list:")
+		for i, skip := range skipList {
+			log.Infof("%d.\t%s: %s", i+1, skip.Name, skip.Message)
+		}
+	}
+	log.Info("===============================================================")
+}
+
+func (logListener) soakSummary(report *RunReport) {
+	log.Info("===============================================================")
+	log.Infof("Zion Tool Soak Finish Iterations:%v Total:%v Success:%v Failed:%v Skip:%v, SpendTime:%.0f sec",
+		report.Iterations,
+		report.Total,
+		report.Success,
+		report.Failed,
+		report.Skipped,
+		report.Duration,
+	)
+	log.Info("---------------------------------------------------------------")
+	for i, s := range report.Soak {
+		log.Infof("%d.\t%s runs:%d success:%d failed:%d skip:%d latency min:%.3fs avg:%.3fs p95:%.3fs max:%.3fs",
+			i+1, s.Name, s.Runs, s.Success, s.Failed, s.Skipped, s.Min, s.Avg, s.P95, s.Max)
+	}
+	log.Info("===============================================================")
+}
@@ -0,0 +1,96 @@
+package frame
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestListener(t *testing.T) {
+	pt := newTestTool()
+	pt.RegHandler("transfer", func(ctx context.Context) *Result { return Succeed() })
+	pt.RegHandler("register", func(ctx context.Context) *Result { return Fail(errors.New("register failed")) })
+
+	var (
+		mu     sync.Mutex
+		events []*Event
+	)
+	pt.AddListener(ListenerFunc(func(ev *Event) {
+		mu.Lock()
+		defer mu.Unlock()
+		events = append(events, ev)
+	}))
+	pt.Start([]string{"transfer", "register"})
+
+	expect := []EventType{EventRunStart, EventMethodStart, EventMethodFinish, EventMethodStart, EventMethodFinish, EventRunFinish}
+	if len(events) != len(expect) {
+		t.Fatalf("expect %d events, got %d", len(expect), len(events))
+	}
+	for i, ev := range events {
+		if ev.Type != expect[i] || ev.RunID == "" || ev.RunID != events[0].RunID {
+			t.Fatalf("unexpected event %d: %+v", i, ev)
+		}
+	}
+	if events[0].Total != 2 {
+		t.Fatalf("expect total 2, got %d", events[0].Total)
+	}
+	if m := events[2].Method; m.Name != "transfer" || m.Status != StatusSuccess {
+		t.Fatalf("unexpected method event %+v", m)
+	}
+	if m := events[4].Method; m.Name != "register" || m.Status != StatusFailed || m.Message != "register failed" {
+		t.Fatalf("unexpected method event %+v", m)
+	}
+	if events[5].Report != pt.Report() {
+		t.Fatal("expect run finish event carries the report")
+	}
+}
+
+func TestWebhookListener(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received []*Event
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" || r.Header.Get("X-Token") != "secret" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ev := new(Event)
+		if err := json.NewDecoder(r.Body).Decode(ev); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		mu.Lock()
+		received = append(received, ev)
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	wl := NewWebhookListener(srv.URL, EventRunFinish)
+	wl.SetHeader("X-Token", "secret")
+
+	pt := newTestTool()
+	pt.RegHandler("transfer", func(ctx context.Context) *Result { return Succeed() })
+	pt.AddListener(wl)
+	pt.Start([]string{"transfer"})
+
+	if len(received) != 1 {
+		t.Fatalf("expect 1 event posted, got %d", len(received))
+	}
+	ev := received[0]
+	if ev.Type != EventRunFinish || ev.Report == nil || ev.Report.Success != 1 || ev.Report.Methods[0].Name != "transfer" {
+		t.Fatalf("unexpected event posted %+v", ev)
+	}
+
+	failed := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer failed.Close()
+	if err := NewWebhookListener(failed.URL).Post(ev); err == nil {
+		t.Fatal("expect error on non 2xx status")
+	}
+}
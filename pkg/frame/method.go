@@ -0,0 +1,212 @@
+package frame
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrMethodFailed = errors.New("method returned false")
+	ErrTimeout      = errors.New("timed out")
+)
+
+// PanicError is the failure of method which panics, it keeps the stack trace.
+type PanicError struct {
+	Value interface{}
+	Stack string
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Method is the legacy method signature, it only reports success or failure.
+type Method func() bool
+
+// Handler is the context-aware method signature. the returned result carries
+// the failure reason and the metrics collected by the method, a nil result
+// is treated as success.
+type Handler func(ctx context.Context) *Result
+
+// Result is the outcome of a single method execution.
+type Result struct {
+	Err      error
+	Duration time.Duration
+	Metrics  map[string]interface{}
+	TxHashes []string
+	Attempts int
+	// Stack is the stack trace if method panics
+	Stack string
+	// Instances are the results of concurrent instances, see Step.Instances
+	Instances []*Result
+}
+
+func NewResult() *Result {
+	return &Result{Metrics: make(map[string]interface{})}
+}
+
+// Succeed returns a successful result
+func Succeed() *Result {
+	return NewResult()
+}
+
+// Fail returns a failed result with the given error
+func Fail(err error) *Result {
+	return NewResult().Fail(err)
+}
+
+// Failf returns a failed result with the formatted error message
+func Failf(format string, a ...interface{}) *Result {
+	return Fail(fmt.Errorf(format, a...))
+}
+
+func (r *Result) Fail(err error) *Result {
+	r.Err = err
+	return r
+}
+
+func (r *Result) Succeed() bool {
+	return r.Err == nil
+}
+
+func (r *Result) SetMetric(key string, value interface{}) *Result {
+	if r.Metrics == nil {
+		r.Metrics = make(map[string]interface{})
+	}
+	r.Metrics[key] = value
+	return r
+}
+
+// AddTx captures the hash of transaction sent by the method
+func (r *Result) AddTx(hash string) *Result {
+	r.TxHashes = append(r.TxHashes, hash)
+	return r
+}
+
+// Adapt wraps a legacy method into a handler
+func Adapt(method Method) Handler {
+	return func(ctx context.Context) *Result {
+		if !method() {
+			return Fail(ErrMethodFailed)
+		}
+		return Succeed()
+	}
+}
+
+type methodEntry struct {
+	name    string
+	handler Handler
+	deps    []string
+	retry   *RetryPolicy
+	info    MethodInfo
+}
+
+// Option customize method registration
+type Option func(entry *methodEntry)
+
+// DependsOn declares the methods which should be finished successfully
+// before this one, dependencies absent from the run list are ignored.
+func DependsOn(names ...string) Option {
+	return func(entry *methodEntry) {
+		entry.deps = append(entry.deps, names...)
+	}
+}
+
+// Step is a single method invocation in a run, the same method can appear
+// in several steps with different params.
+type Step struct {
+	// Name identifies the step in logs and reports, default to method name
+	Name   string
+	Method string
+	// Params is passed to the handler inline, methods fallback to their
+	// case files if it's empty.
+	Params json.RawMessage `json:",omitempty"`
+	// DependsOn lists step or method names which should succeed before this step
+	DependsOn []string `json:",omitempty"`
+	// Instances is the number of method instances running concurrently, the
+	// default one set by PaletteTool is used for Concurrent methods if it's 0,
+	// and other methods run as a single instance.
+	Instances int `json:",omitempty"`
+}
+
+// name returns the step name defaulted to method name, the step owned by
+// caller is never modified by the tool.
+func (s *Step) name() string {
+	if s.Name == "" {
+		return s.Method
+	}
+	return s.Name
+}
+
+// NewSteps converts method names into steps without params
+func NewSteps(methods []string) []*Step {
+	steps := make([]*Step, 0, len(methods))
+	for _, method := range methods {
+		steps = append(steps, &Step{Name: method, Method: method})
+	}
+	return steps
+}
+
+type stepKey struct{}
+
+// stepContext is the running step in context, it records the params which
+// are actually used by the method.
+type stepContext struct {
+	step   *Step
+	mu     sync.Mutex
+	params json.RawMessage
+}
+
+func withStep(ctx context.Context, sc *stepContext) context.Context {
+	return context.WithValue(ctx, stepKey{}, sc)
+}
+
+// usedParams returns the params recorded by method, and the inline params
+// of step if nothing recorded.
+func (sc *stepContext) usedParams() json.RawMessage {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if len(sc.params) > 0 {
+		return sc.params
+	}
+	return sc.step.Params
+}
+
+// StepFrom returns the step which is running in the context
+func StepFrom(ctx context.Context) *Step {
+	if sc, ok := ctx.Value(stepKey{}).(*stepContext); ok {
+		return sc.step
+	}
+	return nil
+}
+
+// StepParams returns the inline params of the running step, it's empty if
+// the method is not invoked with params.
+func StepParams(ctx context.Context) json.RawMessage {
+	if step := StepFrom(ctx); step != nil {
+		return step.Params
+	}
+	return nil
+}
+
+// RecordParams records the params loaded by method, e.g: from case file, so
+// that the run record replays the same params. it does nothing if the method
+// is not invoked by PaletteTool.
+func RecordParams(ctx context.Context, params interface{}) error {
+	sc, ok := ctx.Value(stepKey{}).(*stepContext)
+	if !ok {
+		return nil
+	}
+	enc, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.params = enc
+	return nil
+}
@@ -0,0 +1,103 @@
+package frame
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/files"
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+// RunRecord is the outcome of a run persisted in workspace, it's used to
+// rerun the failed and skipped steps.
+type RunRecord struct {
+	RunID string
+	Start time.Time
+	End   time.Time
+	Steps []*StepRecord
+}
+
+// StepRecord is the step with the params it used and its outcome
+type StepRecord struct {
+	Name      string
+	Method    string
+	Params    json.RawMessage `json:",omitempty"`
+	DependsOn []string        `json:",omitempty"`
+	Instances int             `json:",omitempty"`
+	Status    Status
+	Message   string `json:",omitempty"`
+}
+
+func newRunRecord(runID string, start, end time.Time, tasks []*task) *RunRecord {
+	r := &RunRecord{
+		RunID: runID,
+		Start: start,
+		End:   end,
+		Steps: make([]*StepRecord, 0, len(tasks)),
+	}
+	for _, t := range tasks {
+		m := newMethodReport(t, t.status)
+		params := t.params
+		if len(params) == 0 {
+			params = t.step.Params
+		}
+		r.Steps = append(r.Steps, &StepRecord{
+			Name:      t.name,
+			Method:    t.step.Method,
+			Params:    params,
+			DependsOn: t.step.DependsOn,
+			Instances: t.step.Instances,
+			Status:    m.Status,
+			Message:   m.Message,
+		})
+	}
+	return r
+}
+
+// LoadRunRecord reads the run record persisted by the last run
+func LoadRunRecord(path string) (*RunRecord, error) {
+	r := new(RunRecord)
+	if err := files.ReadJsonFile(path, r); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Save writes the record to path
+func (r *RunRecord) Save(path string) error {
+	return files.WriteJsonFile(path, r, true)
+}
+
+// FailedSteps returns the failed and skipped steps in the original order,
+// with the params they used in the recorded run.
+func (r *RunRecord) FailedSteps() []*Step {
+	steps := make([]*Step, 0)
+	for _, s := range r.Steps {
+		if s.Status == StatusSuccess {
+			continue
+		}
+		steps = append(steps, &Step{
+			Name:      s.Name,
+			Method:    s.Method,
+			Params:    s.Params,
+			DependsOn: s.DependsOn,
+			Instances: s.Instances,
+		})
+	}
+	return steps
+}
+
+// SetRecordPath persists the outcome of every run to path, it's disabled if
+// path is empty.
+func (pt *PaletteTool) SetRecordPath(path string) {
+	pt.recordPath = path
+}
+
+func (pt *PaletteTool) saveRecord(start, end time.Time, tasks []*task) {
+	if pt.recordPath == "" {
+		return
+	}
+	if err := newRunRecord(pt.runID, start, end, tasks).Save(pt.recordPath); err != nil {
+		log.Errorf("failed to save run record to %s, err: %v", pt.recordPath, err)
+	}
+}
@@ -0,0 +1,139 @@
+package frame
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRunRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "last_run.json")
+
+	type transferParam struct {
+		Amount int
+	}
+	failed := true
+	pt := newTestTool()
+	pt.SetRecordPath(path)
+	pt.RegHandler("transfer", func(ctx context.Context) *Result {
+		param := &transferParam{Amount: 100}
+		if len(StepParams(ctx)) > 0 {
+			if err := json.Unmarshal(StepParams(ctx), param); err != nil {
+				return Fail(err)
+			}
+		} else if err := RecordParams(ctx, param); err != nil {
+			return Fail(err)
+		}
+		if failed {
+			return Fail(errors.New("transfer failed"))
+		}
+		return Succeed().SetMetric("amount", param.Amount)
+	})
+	pt.RegMethod("demo", func() bool { return true })
+	pt.RegMethod("stake", func() bool { return true }, DependsOn("transfer"))
+	pt.Start([]string{"demo", "transfer", "stake"})
+
+	record, err := LoadRunRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if record.RunID == "" || len(record.Steps) != 3 {
+		t.Fatalf("unexpected record %+v", record)
+	}
+	s := record.Steps[1]
+	recorded := new(transferParam)
+	if err := json.Unmarshal(s.Params, recorded); err != nil {
+		t.Fatal(err)
+	}
+	if s.Status != StatusFailed || s.Message != "transfer failed" || recorded.Amount != 100 {
+		t.Fatalf("unexpected step record %+v", s)
+	}
+
+	steps := record.FailedSteps()
+	if len(steps) != 2 || steps[0].Name != "transfer" || steps[1].Name != "stake" {
+		t.Fatalf("unexpected failed steps %+v", steps)
+	}
+
+	failed = false
+	pt.StartSteps(steps)
+	report := pt.Report()
+	if report.Success != 2 || report.Methods[0].Metrics["amount"] != 100 {
+		t.Fatalf("unexpected rerun report %+v", report.Methods[0])
+	}
+	if record, err = LoadRunRecord(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.FailedSteps()) != 0 {
+		t.Fatalf("expect no failed steps after rerun, got %+v", record.FailedSteps())
+	}
+
+	// instances of step are rerun as recorded
+	failed = true
+	pt.StartSteps([]*Step{{Name: "transfer", Method: "transfer", Instances: 2}})
+	if record, err = LoadRunRecord(path); err != nil {
+		t.Fatal(err)
+	}
+	if record.Steps[0].Instances != 2 {
+		t.Fatalf("unexpected step record %+v", record.Steps[0])
+	}
+	if steps = record.FailedSteps(); len(steps) != 1 || steps[0].Instances != 2 {
+		t.Fatalf("unexpected failed steps %+v", steps)
+	}
+}
+
+func TestSoakRecord(t *testing.T) {
+	dir, err := ioutil.TempDir("", "record")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "last_run.json")
+
+	var (
+		count int
+		total = -1
+	)
+	pt := newTestTool()
+	pt.SetRecordPath(path)
+	pt.AddListener(ListenerFunc(func(ev *Event) {
+		if ev.Type == EventRunStart {
+			total = ev.Total
+		}
+	}))
+	pt.RegHandler("transfer", func(ctx context.Context) *Result {
+		count++
+		time.Sleep(10 * time.Millisecond)
+		if count == 1 {
+			return Fail(errors.New("first"))
+		}
+		return Succeed()
+	})
+	pt.RegMethod("header", func() bool { return true })
+	pt.Soak(NewSteps([]string{"transfer", "header"}), 50*time.Millisecond)
+
+	if total != 0 {
+		t.Fatalf("expect no total at the start of soak, got %d", total)
+	}
+	record, err := LoadRunRecord(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Steps) != 2 {
+		t.Fatalf("expect one record of each step, got %d", len(record.Steps))
+	}
+	// transfer failed in the first iteration only
+	steps := record.FailedSteps()
+	if len(steps) != 1 || steps[0].Name != "transfer" {
+		t.Fatalf("unexpected failed steps %+v", steps)
+	}
+}
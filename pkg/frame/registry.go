@@ -0,0 +1,191 @@
+package frame
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+// MethodInfo describes a registered method
+type MethodInfo struct {
+	Name        string
+	Description string
+	Tags        []string
+	DependsOn   []string
+	// CaseFile is the params file name in `Workspace/cases`
+	CaseFile string
+	// Params is the type of params struct decoded from case file or step params
+	Params reflect.Type
+	// Concurrent methods run as the default number of instances, see SetInstances
+	Concurrent bool
+}
+
+// WithDescription set the method description shown in command `methods` and `describe`
+func WithDescription(desc string) Option {
+	return func(entry *methodEntry) {
+		entry.info.Description = desc
+	}
+}
+
+// WithTags labels the method, e.g: epoch, transfer, smoke
+func WithTags(tags ...string) Option {
+	return func(entry *methodEntry) {
+		entry.info.Tags = append(entry.info.Tags, tags...)
+	}
+}
+
+// WithParams declares the case file and params struct of method, params
+// could be a struct value or pointer to struct.
+func WithParams(caseFile string, params interface{}) Option {
+	return func(entry *methodEntry) {
+		typ := reflect.TypeOf(params)
+		for typ != nil && typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		entry.info.CaseFile = caseFile
+		entry.info.Params = typ
+	}
+}
+
+// Methods returns registered methods in registration order
+func (pt *PaletteTool) Methods() []*MethodInfo {
+	list := make([]*MethodInfo, 0, len(pt.names))
+	for _, name := range pt.names {
+		list = append(list, pt.methodsMap[name].describe())
+	}
+	return list
+}
+
+// Describe returns the method info by name
+func (pt *PaletteTool) Describe(name string) (*MethodInfo, error) {
+	entry := pt.getMethodByName(name)
+	if entry == nil {
+		return nil, fmt.Errorf("method %s not registered", name)
+	}
+	return entry.describe(), nil
+}
+
+func (entry *methodEntry) describe() *MethodInfo {
+	info := entry.info
+	info.Name = entry.name
+	info.DependsOn = entry.deps
+	return &info
+}
+
+// SampleParams generates indented json params of the method, every slice in
+// params struct contains one zero element so that the layout is visible.
+func (info *MethodInfo) SampleParams() ([]byte, error) {
+	if info.Params == nil {
+		return nil, fmt.Errorf("method %s has no params", info.Name)
+	}
+	sample := reflect.New(info.Params)
+	fillSample(sample.Elem())
+	return json.MarshalIndent(sample.Interface(), "", "    ")
+}
+
+func fillSample(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.Type().Elem().Kind() == reflect.Struct {
+			v.Set(reflect.New(v.Type().Elem()))
+			fillSample(v.Elem())
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if v.Type().Field(i).PkgPath == "" {
+				fillSample(v.Field(i))
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fillSample(v.Index(0))
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+	}
+}
+
+// GenerateCases writes sample case file of every method with params into dir,
+// existing files are kept untouched. it returns the files written.
+func (pt *PaletteTool) GenerateCases(dir string) ([]string, error) {
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	written := make([]string, 0)
+	for _, info := range pt.Methods() {
+		if info.Params == nil || info.CaseFile == "" {
+			continue
+		}
+		path := filepath.Join(dir, info.CaseFile)
+		if _, err := os.Stat(path); err == nil {
+			log.Infof("case file %s exist, skip method %s", path, info.Name)
+			continue
+		}
+		enc, err := info.SampleParams()
+		if err != nil {
+			return written, err
+		}
+		if err := ioutil.WriteFile(path, enc, os.ModePerm); err != nil {
+			return written, err
+		}
+		written = append(written, path)
+	}
+	return written, nil
+}
+
+// PrintMethods logs all registered methods
+func (pt *PaletteTool) PrintMethods() {
+	log.Info("===============================================================")
+	log.Info("Registered methods:")
+	for i, info := range pt.Methods() {
+		line := fmt.Sprintf("%d.\t%s", i+1, info.Name)
+		if len(info.Tags) > 0 {
+			line += fmt.Sprintf(" [%s]", strings.Join(info.Tags, ","))
+		}
+		if info.Description != "" {
+			line += "\t" + info.Description
+		}
+		log.Info(line)
+	}
+	log.Info("===============================================================")
+}
+
+// PrintMethod logs method description, dependencies and params layout
+func (pt *PaletteTool) PrintMethod(name string) error {
+	info, err := pt.Describe(name)
+	if err != nil {
+		return err
+	}
+
+	log.Info("===============================================================")
+	log.Infof("Method:      %s", info.Name)
+	log.Infof("Description: %s", info.Description)
+	log.Infof("Tags:        %s", strings.Join(info.Tags, ","))
+	log.Infof("DependsOn:   %s", strings.Join(info.DependsOn, ","))
+	log.Infof("Concurrent:  %v", info.Concurrent)
+	if info.Params != nil && info.Params.Kind() == reflect.Struct {
+		log.Infof("CaseFile:    %s", info.CaseFile)
+		log.Info("Params:")
+		for i := 0; i < info.Params.NumField(); i++ {
+			field := info.Params.Field(i)
+			if field.PkgPath != "" {
+				continue
+			}
+			log.Infof("\t%s\t%s\t%s", field.Name, field.Type, field.Tag.Get("desc"))
+		}
+		if sample, err := info.SampleParams(); err == nil {
+			log.Infof("Sample:\n%s", sample)
+		}
+	}
+	log.Info("===============================================================")
+	return nil
+}
@@ -0,0 +1,73 @@
+package frame
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type testTransferParam struct {
+	To     []string `desc:"receiver addresses"`
+	Amount uint64   `desc:"amount in ether"`
+	Nested []*struct {
+		Index int
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	pt := newTestTool()
+	pt.RegMethod("transfer", func() bool { return true },
+		WithDescription("transfer native token"),
+		WithTags("transfer", "smoke"),
+		WithParams("test_transfer.json", &testTransferParam{}),
+	)
+	pt.RegMethod("demo", func() bool { return true })
+
+	list := pt.Methods()
+	if len(list) != 2 || list[0].Name != "transfer" || list[1].Name != "demo" {
+		t.Fatalf("unexpected methods %v", list)
+	}
+	if list[0].Tags[1] != "smoke" || list[0].CaseFile != "test_transfer.json" {
+		t.Fatalf("unexpected method info %+v", list[0])
+	}
+	if _, err := pt.Describe("unknown"); err == nil {
+		t.Fatal("expect error for unknown method")
+	}
+
+	sample, err := list[0].SampleParams()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expect := `{
+    "To": [
+        ""
+    ],
+    "Amount": 0,
+    "Nested": [
+        {
+            "Index": 0
+        }
+    ]
+}`
+	if string(sample) != expect {
+		t.Fatalf("unexpected sample %s", sample)
+	}
+
+	dir, err := ioutil.TempDir("", "cases")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	written, err := pt.GenerateCases(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(written) != 1 || written[0] != filepath.Join(dir, "test_transfer.json") {
+		t.Fatalf("unexpected case files %v", written)
+	}
+	if written, _ = pt.GenerateCases(dir); len(written) != 0 {
+		t.Fatalf("existing case files should not be overwritten")
+	}
+}
@@ -0,0 +1,214 @@
+package frame
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/files"
+)
+
+const reportSuiteName = "zion-tool"
+
+// MethodReport is the outcome of a single method in the run report
+type MethodReport struct {
+	Index     int
+	Iteration int `json:",omitempty"`
+	Name      string
+	Method    string
+	Status    Status
+	Start     *time.Time             `json:",omitempty"`
+	End       *time.Time             `json:",omitempty"`
+	Duration  float64                // seconds
+	Message   string                 `json:",omitempty"`
+	Attempts  int                    `json:",omitempty"`
+	Stack     string                 `json:",omitempty"`
+	TxHashes  []string               `json:",omitempty"`
+	Metrics   map[string]interface{} `json:",omitempty"`
+	Instances []*InstanceReport      `json:",omitempty"`
+}
+
+// RunReport is the outcome of a whole run, it can be dumped as json or junit xml.
+type RunReport struct {
+	Start    time.Time
+	End      time.Time
+	Duration float64 // seconds
+	Total    int
+	Success  int
+	Failed   int
+	Skipped  int
+	Methods  []*MethodReport
+
+	// soak mode only
+	Iterations int          `json:",omitempty"`
+	Soak       []*SoakStats `json:",omitempty"`
+}
+
+func newRunReport(start, end time.Time, tasks []*task) *RunReport {
+	r := &RunReport{
+		Start:    start,
+		End:      end,
+		Duration: end.Sub(start).Seconds(),
+		Total:    len(tasks),
+		Methods:  make([]*MethodReport, 0, len(tasks)),
+	}
+	for _, t := range tasks {
+		m := newMethodReport(t, t.status)
+		switch m.Status {
+		case StatusSuccess:
+			r.Success++
+		case StatusFailed:
+			r.Failed++
+		default:
+			r.Skipped++
+		}
+		r.Methods = append(r.Methods, m)
+	}
+	return r
+}
+
+// newMethodReport builds the report of task with the given status, the
+// status of running task is pending.
+func newMethodReport(t *task, status Status) *MethodReport {
+	m := &MethodReport{
+		Index:     t.index,
+		Iteration: t.iteration,
+		Name:      t.name,
+		Method:    t.step.Method,
+		Status:    status,
+	}
+	switch status {
+	case StatusPending, StatusSuccess, StatusFailed:
+	default:
+		m.Status = StatusSkipped
+		m.Message = t.reason
+	}
+	if !t.start.IsZero() {
+		start := t.start
+		m.Start = &start
+	}
+	if t.res != nil {
+		end := t.end
+		m.End = &end
+		m.Duration = t.res.Duration.Seconds()
+		m.Attempts = t.res.Attempts
+		m.Stack = t.res.Stack
+		m.TxHashes = t.res.TxHashes
+		if len(t.res.Metrics) > 0 {
+			m.Metrics = t.res.Metrics
+		}
+		if t.res.Err != nil {
+			m.Message = t.res.Err.Error()
+		}
+		if len(t.res.Instances) > 0 {
+			m.Instances = newInstanceReports(t.res.Instances)
+		}
+	}
+	return m
+}
+
+// WriteJSON dumps report as indented json file
+func (r *RunReport) WriteJSON(path string) error {
+	return files.WriteJsonFile(path, r, true)
+}
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Time      string          `xml:"time,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitMessage `xml:"failure,omitempty"`
+	Skipped   *junitMessage `xml:"skipped,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitMessage struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+// WriteJUnit dumps report as junit xml file, every method is a test case
+// in the `zion-tool` test suite.
+func (r *RunReport) WriteJUnit(path string) error {
+	suite := junitTestSuite{
+		Name:      reportSuiteName,
+		Tests:     r.Total,
+		Failures:  r.Failed,
+		Skipped:   r.Skipped,
+		Time:      junitTime(r.Duration),
+		Timestamp: r.Start.Format(time.RFC3339),
+		Cases:     make([]junitTestCase, 0, len(r.Methods)),
+	}
+	for _, m := range r.Methods {
+		tc := junitTestCase{
+			Name:      junitCaseName(m),
+			ClassName: reportSuiteName,
+			Time:      junitTime(m.Duration),
+		}
+		switch m.Status {
+		case StatusFailed:
+			tc.Failure = &junitMessage{Message: m.Message, Content: m.Message}
+			if m.Stack != "" {
+				tc.Failure.Content += "\n" + m.Stack
+			}
+		case StatusSkipped:
+			tc.Skipped = &junitMessage{Message: m.Message}
+		}
+		out := make([]string, 0)
+		if m.Attempts > 1 {
+			out = append(out, fmt.Sprintf("attempts: %d", m.Attempts))
+		}
+		if len(m.TxHashes) > 0 {
+			out = append(out, "tx hashes:", strings.Join(m.TxHashes, "\n"))
+		}
+		tc.SystemOut = strings.Join(out, "\n")
+		suite.Cases = append(suite.Cases, tc)
+	}
+
+	enc, err := xml.MarshalIndent(&junitTestSuites{
+		Name:     reportSuiteName,
+		Tests:    suite.Tests,
+		Failures: suite.Failures,
+		Skipped:  suite.Skipped,
+		Time:     suite.Time,
+		Suites:   []junitTestSuite{suite},
+	}, "", "    ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(path, append([]byte(xml.Header), enc...), os.ModePerm)
+}
+
+func junitCaseName(m *MethodReport) string {
+	if m.Iteration > 0 {
+		return fmt.Sprintf("%d.%d.%s", m.Iteration, m.Index, m.Name)
+	}
+	return fmt.Sprintf("%d.%s", m.Index, m.Name)
+}
+
+func junitTime(seconds float64) string {
+	return fmt.Sprintf("%.3f", seconds)
+}
@@ -0,0 +1,66 @@
+package frame
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/dylenfu/zion-tool/pkg/files"
+)
+
+func TestRunReport(t *testing.T) {
+	pt := newTestTool()
+	pt.RegHandler("transfer", func(ctx context.Context) *Result {
+		return Succeed().AddTx("0x01").SetMetric("transfers", 1)
+	})
+	pt.RegHandler("register", func(ctx context.Context) *Result {
+		return Fail(errors.New("register failed"))
+	})
+	pt.RegMethod("stake", func() bool { return true }, DependsOn("register"))
+	pt.Start([]string{"transfer", "register", "stake"})
+
+	report := pt.Report()
+	if report.Total != 3 || report.Success != 1 || report.Failed != 1 || report.Skipped != 1 {
+		t.Fatalf("unexpected report counts %+v", report)
+	}
+
+	dir, err := ioutil.TempDir("", "report")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	jsonPath := filepath.Join(dir, "report.json")
+	if err := report.WriteJSON(jsonPath); err != nil {
+		t.Fatal(err)
+	}
+	loaded := new(RunReport)
+	if err := files.ReadJsonFile(jsonPath, loaded); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.Methods[0].TxHashes[0] != "0x01" || loaded.Methods[1].Message != "register failed" ||
+		loaded.Methods[2].Status != StatusSkipped {
+		t.Fatalf("unexpected json report %+v", loaded.Methods)
+	}
+
+	junitPath := filepath.Join(dir, "report.xml")
+	if err := report.WriteJUnit(junitPath); err != nil {
+		t.Fatal(err)
+	}
+	enc, err := ioutil.ReadFile(junitPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suites := new(junitTestSuites)
+	if err := xml.Unmarshal(enc, suites); err != nil {
+		t.Fatal(err)
+	}
+	cases := suites.Suites[0].Cases
+	if len(cases) != 3 || cases[0].Failure != nil || cases[1].Failure == nil || cases[2].Skipped == nil {
+		t.Fatalf("unexpected junit report %s", enc)
+	}
+}
@@ -0,0 +1,141 @@
+package frame
+
+import (
+	"errors"
+	"io"
+	"net"
+	"strings"
+	"time"
+)
+
+type Backoff int
+
+const (
+	BackoffFixed Backoff = iota
+	BackoffExponential
+)
+
+// RetryPolicy describes how a failed method is retried. only errors accepted
+// by Retryable are retried, and IsTransient is used if it's nil.
+type RetryPolicy struct {
+	MaxAttempts int
+	Backoff     Backoff
+	Delay       time.Duration
+	MaxDelay    time.Duration
+	Retryable   func(err error) bool
+}
+
+// WithRetry set the retry policy of method
+func WithRetry(policy RetryPolicy) Option {
+	return func(entry *methodEntry) {
+		entry.retry = &policy
+	}
+}
+
+// RetryAll is a classifier which accepts any error
+func RetryAll(err error) bool {
+	return true
+}
+
+func (p *RetryPolicy) shouldRetry(attempt int, err error) bool {
+	if p == nil || err == nil || attempt >= p.MaxAttempts {
+		return false
+	}
+	if p.Retryable != nil {
+		return p.Retryable(err)
+	}
+	return IsTransient(err)
+}
+
+// delay returns the waiting time before the next attempt, attempt starts from 1.
+func (p *RetryPolicy) delay(attempt int) time.Duration {
+	d := p.Delay
+	if p.Backoff == BackoffExponential {
+		for i := 1; i < attempt; i++ {
+			d *= 2
+			if p.MaxDelay > 0 && d >= p.MaxDelay {
+				break
+			}
+		}
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	return d
+}
+
+type transientError struct {
+	err error
+}
+
+func (e *transientError) Error() string { return e.err.Error() }
+func (e *transientError) Unwrap() error { return e.err }
+
+// Transient marks the error as transient explicitly, so that it's retried by
+// the default retry classifier.
+func Transient(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &transientError{err: err}
+}
+
+// transientMessages are the fragments of rpc errors which usually disappear
+// after a while, e.g: node restarting or devnet warming up.
+var transientMessages = []string{
+	"connection refused",
+	"connection reset",
+	"broken pipe",
+	"i/o timeout",
+	"no such host",
+	"too many requests",
+	"502 bad gateway",
+	"503 service unavailable",
+	"504 gateway timeout",
+	"header not found",
+	"server is not ready",
+}
+
+// transientSuffixes are how the http transport reports the connection closed
+// by node, e.g: `Post "http://127.0.0.1:8545": EOF`, which survives in the
+// messages of errors formatted by methods with %v.
+var transientSuffixes = []string{
+	": eof",
+	": unexpected eof",
+}
+
+// IsTransient classifies errors which are worth retrying
+func IsTransient(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		return false
+	}
+	var te *transientError
+	if errors.As(err, &te) {
+		return true
+	}
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	var ne net.Error
+	if errors.As(err, &ne) && ne.Timeout() {
+		return true
+	}
+
+	msg := strings.ToLower(err.Error())
+	for _, fragment := range transientMessages {
+		if strings.Contains(msg, fragment) {
+			return true
+		}
+	}
+	for _, suffix := range transientSuffixes {
+		if strings.HasSuffix(msg, suffix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package frame
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"testing"
+	"time"
+)
+
+func TestRetryDelay(t *testing.T) {
+	policy := &RetryPolicy{
+		MaxAttempts: 5,
+		Backoff:     BackoffExponential,
+		Delay:       time.Second,
+		MaxDelay:    5 * time.Second,
+	}
+	expect := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	for i, d := range expect {
+		if got := policy.delay(i + 1); got != d {
+			t.Errorf("attempt %d expect delay %v, got %v", i+1, d, got)
+		}
+	}
+
+	policy.Backoff = BackoffFixed
+	if got := policy.delay(3); got != time.Second {
+		t.Errorf("expect fixed delay %v, got %v", time.Second, got)
+	}
+}
+
+func TestIsTransient(t *testing.T) {
+	var testdata = []struct {
+		err    error
+		expect bool
+	}{
+		{nil, false},
+		{errors.New("dial tcp 127.0.0.1:8545: connect: connection refused"), true},
+		{errors.New("503 Service Unavailable"), true},
+		{Transient(errors.New("custom")), true},
+		{errors.New("execution reverted"), false},
+		{io.EOF, true},
+		{fmt.Errorf("failed to get header, err: %w", io.ErrUnexpectedEOF), true},
+		{errors.New(`failed to get header, err: Post "http://127.0.0.1:8545": EOF`), true},
+		{errors.New("invalid params: geofence"), false},
+		{errors.New("EOF marker missing in payload"), false},
+		{ErrMethodFailed, false},
+	}
+	for _, v := range testdata {
+		if got := IsTransient(v.err); got != v.expect {
+			t.Errorf("error %v expect transient %v, got %v", v.err, v.expect, got)
+		}
+	}
+}
+
+func TestMethodRetry(t *testing.T) {
+	pt := newTestTool()
+
+	calls := 0
+	pt.RegHandler("flaky", func(ctx context.Context) *Result {
+		calls++
+		if calls < 3 {
+			return Fail(errors.New("connection refused"))
+		}
+		return Succeed()
+	}, WithRetry(RetryPolicy{MaxAttempts: 5, Delay: time.Millisecond}))
+
+	permanent := 0
+	pt.RegHandler("broken", func(ctx context.Context) *Result {
+		permanent++
+		return Fail(errors.New("execution reverted"))
+	}, WithRetry(RetryPolicy{MaxAttempts: 5, Delay: time.Millisecond}))
+
+	pt.Start([]string{"flaky", "broken"})
+	report := pt.Report()
+	if report.Methods[0].Status != StatusSuccess || report.Methods[0].Attempts != 3 {
+		t.Fatalf("expect flaky success after 3 attempts, got %+v", report.Methods[0])
+	}
+	if report.Methods[1].Status != StatusFailed || report.Methods[1].Attempts != 1 || permanent != 1 {
+		t.Fatalf("expect broken failed without retry, got %+v", report.Methods[1])
+	}
+}
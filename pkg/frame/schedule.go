@@ -0,0 +1,223 @@
+package frame
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+type Status string
+
+const (
+	StatusPending Status = "pending"
+	StatusSuccess Status = "success"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// task is a single method execution in a run
+type task struct {
+	index     int
+	iteration int
+	name      string
+	step      *Step
+	entry     *methodEntry
+	deps      []*task
+	status    Status
+	reason    string
+	res       *Result
+	params    json.RawMessage
+	start     time.Time
+	end       time.Time
+}
+
+func (t *task) finished() bool {
+	return t.status != StatusPending
+}
+
+func (t *task) skip(format string, a ...interface{}) {
+	t.status = StatusSkipped
+	t.reason = fmt.Sprintf(format, a...)
+}
+
+// buildTasks converts steps to tasks and resolves the dependency graph, a
+// dependency matches both step name and method name. steps whose method is
+// not registered or inside a dependency cycle are skipped directly.
+func (pt *PaletteTool) buildTasks(steps []*Step) []*task {
+	tasks := make([]*task, 0, len(steps))
+	byName := make(map[string][]*task)
+	for i, step := range steps {
+		t := &task{index: i + 1, name: step.name(), step: step, status: StatusPending}
+		if t.entry = pt.getMethodByName(step.Method); t.entry == nil {
+			t.skip("method %s not registered", step.Method)
+		}
+		tasks = append(tasks, t)
+		byName[t.name] = append(byName[t.name], t)
+		if step.Method != t.name {
+			byName[step.Method] = append(byName[step.Method], t)
+		}
+	}
+
+	for _, t := range tasks {
+		if t.entry == nil {
+			continue
+		}
+		deps := append(append([]string{}, t.entry.deps...), t.step.DependsOn...)
+		seen := map[*task]bool{t: true}
+		for _, dep := range deps {
+			for _, d := range byName[dep] {
+				if !seen[d] {
+					seen[d] = true
+					t.deps = append(t.deps, d)
+				}
+			}
+		}
+	}
+
+	// mark tasks in dependency cycle with dfs colors
+	const (
+		white = iota
+		gray
+		black
+	)
+	color := make(map[*task]int)
+	var visit func(t *task) bool
+	visit = func(t *task) bool {
+		color[t] = gray
+		cyclic := false
+		for _, dep := range t.deps {
+			switch color[dep] {
+			case gray:
+				cyclic = true
+			case white:
+				if visit(dep) {
+					cyclic = true
+				}
+			}
+		}
+		color[t] = black
+		if cyclic && !t.finished() {
+			t.skip("dependency cycle detected")
+		}
+		return cyclic
+	}
+	for _, t := range tasks {
+		if color[t] == white {
+			visit(t)
+		}
+	}
+	return tasks
+}
+
+// schedule runs tasks in list order with at most `workers` methods at the same
+// time. a task starts only after all of its dependencies succeeded, and it is
+// skipped if any dependency failed or skipped. the dispatcher rests `interval`
+// after each method finished before starting the next one, so that workers
+// are released as soon as methods finished.
+func (pt *PaletteTool) schedule(ctx context.Context, tasks []*task) {
+	var (
+		pending   = make([]*task, 0, len(tasks))
+		finished  = make(chan *task)
+		running   = 0
+		stopped   = ""
+		restUntil time.Time
+	)
+	for _, t := range tasks {
+		if !t.finished() {
+			pending = append(pending, t)
+		}
+	}
+
+	run := func(t *task) {
+		pt.runMethod(ctx, t)
+		finished <- t
+	}
+
+	for {
+		resting := false
+		for progress := true; progress; {
+			progress = false
+			rest := pending[:0]
+			for _, t := range pending {
+				ready, reason := t.ready()
+				switch {
+				case ctx.Err() != nil:
+					t.skip("run cancelled: %v", ctx.Err())
+				case stopped != "":
+					t.skip("fail fast: %s failed", stopped)
+				case reason != "":
+					t.skip(reason)
+				case ready && running < pt.workers && time.Now().Before(restUntil):
+					resting = true
+					rest = append(rest, t)
+					continue
+				case ready && running < pt.workers:
+					running++
+					go run(t)
+				default:
+					rest = append(rest, t)
+					continue
+				}
+				progress = true
+			}
+			pending = rest
+		}
+
+		if running == 0 && !resting {
+			break
+		}
+
+		var (
+			wake   <-chan time.Time
+			cancel <-chan struct{}
+		)
+		if resting {
+			wake = time.After(time.Until(restUntil))
+			cancel = ctx.Done()
+		}
+		select {
+		case <-wake:
+			continue
+		case <-cancel:
+			continue
+		case t := <-finished:
+			running--
+			if pt.interval > 0 {
+				restUntil = time.Now().Add(pt.interval)
+			}
+			if t.res.Succeed() {
+				t.status = StatusSuccess
+			} else {
+				t.status = StatusFailed
+				if pt.failFast && stopped == "" {
+					stopped = t.name
+				}
+			}
+			if err := pt.state.Save(); err != nil {
+				log.Errorf("failed to save state, err: %v", err)
+			}
+		}
+	}
+
+	for _, t := range pending {
+		t.skip("dependencies never finished")
+	}
+}
+
+// ready returns true if all dependencies succeeded, or the skip reason if
+// any of them failed.
+func (t *task) ready() (bool, string) {
+	for _, dep := range t.deps {
+		switch dep.status {
+		case StatusPending:
+			return false, ""
+		case StatusSuccess:
+		default:
+			return false, fmt.Sprintf("dependency %s %s", dep.name, dep.status)
+		}
+	}
+	return true, ""
+}
@@ -0,0 +1,103 @@
+package frame
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+const (
+	tagPrefix     = "tag:"
+	excludePrefix = "!"
+)
+
+// Select resolves method selectors into method names, a selector is one of:
+//
+//	name       exact method name
+//	glob       shell pattern matched against names, e.g: "epoch*"
+//	tag:xxx    methods labeled with the tag, e.g: "tag:smoke"
+//	!selector  excludes the methods matched by selector
+//
+// names are returned in selector order, and methods matched by the same glob
+// or tag keep the registration order. all methods are selected if there are
+// only exclusions. it fails if any selector matches nothing.
+func (pt *PaletteTool) Select(selectors []string) ([]string, error) {
+	var (
+		selected = make([]string, 0)
+		seen     = make(map[string]bool)
+		excluded = make(map[string]bool)
+		included = false
+	)
+	for _, selector := range selectors {
+		selector = strings.TrimSpace(selector)
+		if selector == "" {
+			continue
+		}
+
+		exclude := strings.HasPrefix(selector, excludePrefix)
+		names, err := pt.match(strings.TrimPrefix(selector, excludePrefix))
+		if err != nil {
+			return nil, err
+		}
+		if len(names) == 0 {
+			return nil, fmt.Errorf("selector %s matches no method", selector)
+		}
+
+		for _, name := range names {
+			if exclude {
+				excluded[name] = true
+			} else if !seen[name] {
+				seen[name] = true
+				selected = append(selected, name)
+			}
+		}
+		if !exclude {
+			included = true
+		}
+	}
+	if !included && len(excluded) > 0 {
+		selected = append(selected, pt.names...)
+	}
+
+	list := make([]string, 0, len(selected))
+	for _, name := range selected {
+		if !excluded[name] {
+			list = append(list, name)
+		}
+	}
+	return list, nil
+}
+
+// match returns the method names matched by a selector without exclusion
+func (pt *PaletteTool) match(selector string) ([]string, error) {
+	if tag := strings.TrimPrefix(selector, tagPrefix); tag != selector {
+		names := make([]string, 0)
+		for _, name := range pt.names {
+			for _, t := range pt.methodsMap[name].info.Tags {
+				if t == tag {
+					names = append(names, name)
+					break
+				}
+			}
+		}
+		return names, nil
+	}
+
+	if _, exist := pt.methodsMap[selector]; exist {
+		return []string{selector}, nil
+	}
+	if !strings.ContainsAny(selector, "*?[") {
+		return nil, nil
+	}
+	names := make([]string, 0)
+	for _, name := range pt.names {
+		ok, err := path.Match(selector, name)
+		if err != nil {
+			return nil, fmt.Errorf("invalid selector %s: %v", selector, err)
+		}
+		if ok {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
@@ -0,0 +1,43 @@
+package frame
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSelect(t *testing.T) {
+	pt := newTestTool()
+	ok := func() bool { return true }
+	pt.RegMethod("demo", ok, WithTags("smoke"))
+	pt.RegMethod("transfer", ok, WithTags("transfer", "smoke"))
+	pt.RegMethod("register", ok, WithTags("epoch"))
+	pt.RegMethod("stake", ok, WithTags("epoch"))
+	pt.RegMethod("epoch-list", ok, WithTags("epoch", "smoke"))
+
+	cases := []struct {
+		selectors []string
+		expect    []string
+	}{
+		{[]string{"stake", "demo"}, []string{"stake", "demo"}},
+		{[]string{"tag:epoch"}, []string{"register", "stake", "epoch-list"}},
+		{[]string{"tag:smoke", "!tag:epoch"}, []string{"demo", "transfer"}},
+		{[]string{"epoch*", "re?ister"}, []string{"epoch-list", "register"}},
+		{[]string{"!tag:smoke"}, []string{"register", "stake"}},
+		{[]string{" demo ", "demo", "!demo", "stake"}, []string{"stake"}},
+	}
+	for _, c := range cases {
+		got, err := pt.Select(c.selectors)
+		if err != nil {
+			t.Fatalf("select %v, err: %v", c.selectors, err)
+		}
+		if !reflect.DeepEqual(got, c.expect) {
+			t.Fatalf("select %v, expect %v, got %v", c.selectors, c.expect, got)
+		}
+	}
+
+	for _, selectors := range [][]string{{"unknown"}, {"tag:unknown"}, {"x*"}, {"demo", "!unknown"}, {"[a-"}} {
+		if _, err := pt.Select(selectors); err == nil {
+			t.Fatalf("expect error for %v", selectors)
+		}
+	}
+}
@@ -0,0 +1,138 @@
+package frame
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+// SoakStats aggregates results of the same step in all soak iterations,
+// latencies only count the executed ones.
+type SoakStats struct {
+	Name    string
+	Runs    int
+	Success int
+	Failed  int
+	Skipped int
+	Min     float64 // seconds
+	Avg     float64 // seconds
+	P95     float64 // seconds
+	Max     float64 // seconds
+}
+
+// Soak repeats steps until period elapsed, the iteration in progress is
+// not interrupted when period reached.
+func (pt *PaletteTool) Soak(steps []*Step, period time.Duration) {
+	if len(steps) == 0 || period <= 0 {
+		log.Info("No method to run")
+		return
+	}
+
+	var (
+		start    = time.Now()
+		deadline = start.Add(period)
+		all      = make([]*task, 0)
+		ctx      = withState(context.Background(), pt.state)
+	)
+
+	// the number of methods depends on the iterations finished in period
+	pt.onStart(0)
+	log.Infof("Soak %d methods for %v, until %s", len(steps), period, deadline.Format(time.RFC3339))
+
+	iteration := 0
+	for time.Now().Before(deadline) {
+		iteration++
+		iterStart := time.Now()
+		tasks := pt.buildTasks(steps)
+		for _, t := range tasks {
+			t.iteration = iteration
+		}
+		pt.schedule(ctx, tasks)
+		all = append(all, tasks...)
+
+		report := newRunReport(iterStart, time.Now(), tasks)
+		log.Infof("Soak Iteration:%d Total:%v Success:%v Failed:%v Skip:%v, SpendTime:%v",
+			iteration, report.Total, report.Success, report.Failed, report.Skipped, time.Since(iterStart))
+		if pt.failFast && report.Failed > 0 {
+			log.Infof("Soak stopped at iteration %d by fail fast", iteration)
+			break
+		}
+	}
+
+	end := time.Now()
+	pt.report = newRunReport(start, end, all)
+	pt.report.Iterations = iteration
+	pt.report.Soak = soakStats(steps, all)
+	pt.saveRecord(start, end, soakRecordTasks(len(steps), all))
+	pt.onFinish(pt.report)
+}
+
+// soakRecordTasks picks one task of every step for the run record, the last
+// failed or skipped one if the step ever failed, so that steps failed in any
+// iteration are rerun once.
+func soakRecordTasks(steps int, tasks []*task) []*task {
+	picked := make([]*task, steps)
+	for _, t := range tasks {
+		p := picked[t.index-1]
+		if p == nil || p.status == StatusSuccess || t.status != StatusSuccess {
+			picked[t.index-1] = t
+		}
+	}
+	list := make([]*task, 0, steps)
+	for _, t := range picked {
+		if t != nil {
+			list = append(list, t)
+		}
+	}
+	return list
+}
+
+func soakStats(steps []*Step, tasks []*task) []*SoakStats {
+	var (
+		list      = make([]*SoakStats, 0, len(steps))
+		byName    = make(map[string]*SoakStats)
+		latencies = make(map[string][]time.Duration)
+	)
+	for _, step := range steps {
+		if _, ok := byName[step.name()]; !ok {
+			stats := &SoakStats{Name: step.name()}
+			byName[step.name()] = stats
+			list = append(list, stats)
+		}
+	}
+
+	for _, t := range tasks {
+		stats := byName[t.name]
+		stats.Runs++
+		switch t.status {
+		case StatusSuccess:
+			stats.Success++
+		case StatusFailed:
+			stats.Failed++
+		default:
+			stats.Skipped++
+		}
+		if t.res != nil {
+			latencies[t.name] = append(latencies[t.name], t.res.Duration)
+		}
+	}
+
+	for _, stats := range list {
+		ds := latencies[stats.Name]
+		if len(ds) == 0 {
+			continue
+		}
+		sort.Slice(ds, func(i, j int) bool { return ds[i] < ds[j] })
+		var sum time.Duration
+		for _, d := range ds {
+			sum += d
+		}
+		stats.Min = ds[0].Seconds()
+		stats.Max = ds[len(ds)-1].Seconds()
+		stats.Avg = (sum / time.Duration(len(ds))).Seconds()
+		stats.P95 = ds[(len(ds)*95+99)/100-1].Seconds()
+	}
+	return list
+}
@@ -0,0 +1,126 @@
+package frame
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/dylenfu/zion-tool/pkg/files"
+)
+
+// State is the key/value bag shared by methods in a run, e.g: validators
+// created by `register` are read by `stake` later. values are stored in json
+// so that the state can be persisted and loaded by the next run.
+type State struct {
+	mu   sync.RWMutex
+	data map[string]json.RawMessage
+	path string
+}
+
+func NewState() *State {
+	return &State{data: make(map[string]json.RawMessage)}
+}
+
+// LoadState reads state persisted in path, and saves back to the same path
+// on Save. the state is empty if file not exist.
+func LoadState(path string) (*State, error) {
+	s := NewState()
+	s.path = path
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return s, nil
+	}
+	if err := files.ReadJsonFile(path, &s.data); err != nil {
+		return nil, err
+	}
+	if s.data == nil {
+		s.data = make(map[string]json.RawMessage)
+	}
+	return s, nil
+}
+
+// Set stores json encoded value with key
+func (s *State) Set(key string, value interface{}) error {
+	enc, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[key] = enc
+	return nil
+}
+
+// Get decodes value of key into ptr, it returns false if key not exist.
+func (s *State) Get(key string, ptr interface{}) (bool, error) {
+	s.mu.RLock()
+	enc, ok := s.data[key]
+	s.mu.RUnlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(enc, ptr)
+}
+
+func (s *State) GetString(key string) (string, bool) {
+	var v string
+	ok, err := s.Get(key, &v)
+	return v, ok && err == nil
+}
+
+func (s *State) GetUint64(key string) (uint64, bool) {
+	var v uint64
+	ok, err := s.Get(key, &v)
+	return v, ok && err == nil
+}
+
+func (s *State) Has(key string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.data[key]
+	return ok
+}
+
+func (s *State) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, key)
+}
+
+func (s *State) Keys() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.data))
+	for k := range s.data {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Save persists state to the path it was loaded from, it does nothing for
+// state created by NewState.
+func (s *State) Save() error {
+	if s.path == "" {
+		return nil
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return files.WriteJsonFile(s.path, s.data, true)
+}
+
+type stateKey struct{}
+
+func withState(ctx context.Context, state *State) context.Context {
+	return context.WithValue(ctx, stateKey{}, state)
+}
+
+// StateFrom returns the run state in the context, a detached empty state
+// is returned if the method is not invoked by PaletteTool.
+func StateFrom(ctx context.Context) *State {
+	if state, ok := ctx.Value(stateKey{}).(*State); ok {
+		return state
+	}
+	return NewState()
+}
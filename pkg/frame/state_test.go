@@ -0,0 +1,63 @@
+package frame
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestStateShareAndPersist(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "state.json")
+
+	type validator struct {
+		Index int
+		Addr  string
+	}
+
+	pt := newTestTool()
+	if err := pt.SetStatePath(path); err != nil {
+		t.Fatal(err)
+	}
+	pt.RegHandler("register", func(ctx context.Context) *Result {
+		vals := []*validator{{Index: 1, Addr: "0x01"}}
+		if err := StateFrom(ctx).Set("validators", vals); err != nil {
+			return Fail(err)
+		}
+		return Succeed()
+	})
+	pt.RegHandler("stake", func(ctx context.Context) *Result {
+		var vals []*validator
+		if ok, err := StateFrom(ctx).Get("validators", &vals); !ok || err != nil {
+			return Failf("validators not found, err: %v", err)
+		}
+		if len(vals) != 1 || vals[0].Addr != "0x01" {
+			return Failf("unexpected validators %v", vals)
+		}
+		return Succeed()
+	}, DependsOn("register"))
+	pt.Start([]string{"register", "stake"})
+	if pt.Report().Success != 2 {
+		t.Fatalf("unexpected report %+v", pt.Report().Methods)
+	}
+
+	// next run picks up the state persisted
+	next := newTestTool()
+	if err := next.SetStatePath(path); err != nil {
+		t.Fatal(err)
+	}
+	next.RegHandler("stake", pt.getMethodByName("stake").handler)
+	next.Start([]string{"stake"})
+	if next.Report().Success != 1 {
+		t.Fatalf("unexpected report %+v", next.Report().Methods)
+	}
+	if keys := next.State().Keys(); len(keys) != 1 || keys[0] != "validators" {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+}
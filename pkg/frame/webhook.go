@@ -0,0 +1,91 @@
+package frame
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+const DefaultWebhookTimeout = 10 * time.Second
+
+// WebhookListener posts json encoded events to the url, e.g: chat-ops bot
+// which announces the results. delivery failures are logged and never
+// affect the run.
+type WebhookListener struct {
+	url     string
+	events  map[EventType]bool
+	headers map[string]string
+	client  *http.Client
+}
+
+// NewWebhookListener creates listener posting the events to url, all events
+// are posted if none specified.
+func NewWebhookListener(url string, events ...EventType) *WebhookListener {
+	wl := &WebhookListener{
+		url:     url,
+		events:  make(map[EventType]bool),
+		headers: make(map[string]string),
+		client:  &http.Client{Timeout: DefaultWebhookTimeout},
+	}
+	for _, typ := range events {
+		wl.events[typ] = true
+	}
+	return wl
+}
+
+// SetHeader set the extra http header of requests, e.g: Authorization
+func (wl *WebhookListener) SetHeader(key, value string) {
+	wl.headers[key] = value
+}
+
+// SetTimeout set the timeout of every request
+func (wl *WebhookListener) SetTimeout(d time.Duration) {
+	wl.client.Timeout = d
+}
+
+func (wl *WebhookListener) OnStart(ev *Event)        { wl.post(ev) }
+func (wl *WebhookListener) OnMethodStart(ev *Event)  { wl.post(ev) }
+func (wl *WebhookListener) OnMethodFinish(ev *Event) { wl.post(ev) }
+func (wl *WebhookListener) OnFinish(ev *Event)       { wl.post(ev) }
+
+func (wl *WebhookListener) post(ev *Event) {
+	if len(wl.events) > 0 && !wl.events[ev.Type] {
+		return
+	}
+	if err := wl.Post(ev); err != nil {
+		log.Errorf("failed to post %s event to webhook %s, err: %v", ev.Type, wl.url, err)
+	}
+}
+
+// Post sends the event to webhook regardless of the events filter
+func (wl *WebhookListener) Post(ev *Event) error {
+	enc, err := json.Marshal(ev)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest(http.MethodPost, wl.url, bytes.NewReader(enc))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range wl.headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := wl.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("unexpected status %s: %s", resp.Status, bytes.TrimSpace(body))
+	}
+	return nil
+}
@@ -42,10 +42,31 @@ const (
 	Pink   = "1;35"
 )
 
+var colorEnabled = true
+
 func Color(code, msg string) string {
+	if !colorEnabled {
+		return msg
+	}
 	return fmt.Sprintf("\033[%sm%s\033[m", code, msg)
 }
 
+// SetColor enable or disable ANSI color codes in log output, e.g: colors
+// should be disabled if stdout is not a terminal.
+func SetColor(enable bool) {
+	colorEnabled = enable
+	levels = levelNames()
+}
+
+// IsTerminal returns true if the file is a character device, e.g: console
+func IsTerminal(f *os.File) bool {
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return fi.Mode()&os.ModeCharDevice != 0
+}
+
 const (
 	TraceLog = iota
 	DebugLog
@@ -57,7 +78,13 @@ const (
 )
 
 var (
-	levels = map[int]string{
+	levels = levelNames()
+	Stdout = os.Stdout
+	PATH   = "./Log/"
+)
+
+func levelNames() map[int]string {
+	return map[int]string{
 		DebugLog: Color(Green, "[DEBUG]"),
 		InfoLog:  Color(Cyan, "[INFO ]"),
 		WarnLog:  Color(Yellow, "[WARN ]"),
@@ -65,9 +92,7 @@ var (
 		FatalLog: Color(Red, "[FATAL]"),
 		TraceLog: Color(Pink, "[TRACE]"),
 	}
-	Stdout = os.Stdout
-	PATH   = "./Log/"
-)
+}
 
 const (
 	NAME_PREFIX          = "LEVEL"
@@ -377,6 +402,7 @@ func InitLog(logLevel int, a ...interface{}) {
 			}
 		}
 	}
+	writers = append(writers, subscribers)
 	fileAndStdoutWrite := io.MultiWriter(writers...)
 	Log = New(fileAndStdoutWrite, "", log.LUTC|log.Ldate|log.Lmicroseconds, logLevel, logFile)
 }
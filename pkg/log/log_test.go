@@ -0,0 +1,47 @@
+package log
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSetColor(t *testing.T) {
+	defer SetColor(true)
+
+	if !strings.Contains(LevelName(InfoLog), "\033[") {
+		t.Fatalf("level name should be colored by default")
+	}
+
+	SetColor(false)
+	if name := LevelName(InfoLog); name != "[INFO ]" {
+		t.Fatalf("expect colorless level name, got %q", name)
+	}
+	if NameLevel("[ERROR]") != ErrorLog {
+		t.Fatalf("expect error level")
+	}
+	if msg := Color(Red, "msg"); msg != "msg" {
+		t.Fatalf("expect colorless message, got %q", msg)
+	}
+}
+
+func TestSubscribe(t *testing.T) {
+	InitLog(InfoLog)
+	lines, cancel := Subscribe(2)
+
+	Info("first")
+	Debug("filtered by level")
+	Info("second")
+	Info("dropped since buffer is full")
+	cancel()
+
+	received := make([]string, 0)
+	for line := range lines {
+		received = append(received, line)
+	}
+	if len(received) != 2 || !strings.HasSuffix(received[0], "first") || !strings.HasSuffix(received[1], "second") {
+		t.Fatalf("unexpected lines %q", received)
+	}
+	if strings.Contains(received[0], "\033[") {
+		t.Fatalf("expect colorless line, got %q", received[0])
+	}
+}
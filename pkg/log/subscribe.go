@@ -0,0 +1,77 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package log
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var colorPattern = regexp.MustCompile("\x1b\\[[0-9;]*m")
+
+// subscribers receives every line written by the logger created in InitLog
+var subscribers = &broadcaster{subs: make(map[int]chan string)}
+
+type broadcaster struct {
+	mu   sync.RWMutex
+	subs map[int]chan string
+	next int
+}
+
+// Write sends the line to every subscriber without color codes, lines are
+// dropped for the subscriber whose buffer is full so that logging never blocks.
+func (b *broadcaster) Write(p []byte) (int, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if len(b.subs) == 0 {
+		return len(p), nil
+	}
+
+	line := strings.TrimRight(colorPattern.ReplaceAllString(string(p), ""), "\n")
+	for _, ch := range b.subs {
+		select {
+		case ch <- line:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Subscribe returns the channel receiving log lines and the function to
+// cancel the subscription, buffer is the max number of pending lines.
+func Subscribe(buffer int) (<-chan string, func()) {
+	ch := make(chan string, buffer)
+
+	subscribers.mu.Lock()
+	id := subscribers.next
+	subscribers.next++
+	subscribers.subs[id] = ch
+	subscribers.mu.Unlock()
+
+	var once sync.Once
+	return ch, func() {
+		once.Do(func() {
+			subscribers.mu.Lock()
+			delete(subscribers.subs, id)
+			subscribers.mu.Unlock()
+			close(ch)
+		})
+	}
+}
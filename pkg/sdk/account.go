@@ -3,12 +3,10 @@ package sdk
 import (
 	"context"
 	"crypto/ecdsa"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"math/big"
 	"strings"
-	"sync"
 	"time"
 
 	"github.com/dylenfu/zion-tool/pkg/log"
@@ -23,58 +21,78 @@ import (
 )
 
 var (
-	gasLimit = uint64(210000)
-	gasPrice = new(big.Int).SetUint64(1000000000)
-
 	EmptyHash = common.Hash{}
 )
 
 type Account struct {
-	signer    types.EIP155Signer
+	chainID   *big.Int
+	signer    types.Signer
 	pk        *ecdsa.PrivateKey
 	addr      common.Address
 	url       string
 	client    *ethclient.Client
 	rpcClient *rpc.Client
 
-	nonce   uint64
-	nonceMu *sync.RWMutex
+	nonces *NonceManager
+	gas    GasStrategy
+	fees   FeeEstimator
+	txType uint8
+
+	waitTimeout   time.Duration
+	confirmations uint64
 }
 
 func NewAccount(chainID uint64, url string) (*Account, error) {
+	return NewAccountContext(context.Background(), chainID, url)
+}
+
+func NewAccountContext(ctx context.Context, chainID uint64, url string) (*Account, error) {
 	pk, err := crypto.GenerateKey()
 	if err != nil {
 		return nil, err
 	}
 
-	return CustomNewAccount(chainID, url, pk)
+	return CustomNewAccountContext(ctx, chainID, url, pk)
 }
 
 func CustomNewAccount(chainID uint64, url string, pk *ecdsa.PrivateKey) (*Account, error) {
-	rpcclient, err := rpc.Dial(url)
+	return CustomNewAccountContext(context.Background(), chainID, url, pk)
+}
+
+// CustomNewAccountContext dials the node and fetches the nonce of pk with ctx,
+// the account without pk is read only.
+func CustomNewAccountContext(ctx context.Context, chainID uint64, url string, pk *ecdsa.PrivateKey) (*Account, error) {
+	rpcclient, err := rpc.DialContext(ctx, url)
 	if err != nil {
 		return nil, err
 	}
 	client := ethclient.NewClient(rpcclient)
 
 	acc := &Account{
+		chainID:   new(big.Int).SetUint64(chainID),
 		pk:        pk,
 		url:       url,
 		client:    client,
 		rpcClient: rpcclient,
+		gas:       DefaultGasStrategy(),
+		fees:      DefaultFeeEstimator(),
+		txType:    types.LegacyTxType,
+
+		waitTimeout: DefaultTxWaitTimeout,
 	}
 
 	if pk != nil {
 		address := crypto.PubkeyToAddress(pk.PublicKey)
-		signer := types.NewEIP155Signer(new(big.Int).SetUint64(chainID))
-		curNonce, err := client.NonceAt(context.Background(), address, nil)
-		if err != nil {
-			return nil, err
-		}
+		// london signer signs legacy txs with EIP155 as well
+		signer := types.NewLondonSigner(acc.chainID)
 		acc.signer = signer
 		acc.addr = address
-		acc.nonce = curNonce
-		acc.nonceMu = new(sync.RWMutex)
+		acc.nonces = NewNonceManager(func(ctx context.Context) (uint64, error) {
+			return client.PendingNonceAt(ctx, address)
+		})
+		if _, err := acc.nonces.Resync(ctx); err != nil {
+			return nil, err
+		}
 	}
 
 	return acc, nil
@@ -89,53 +107,98 @@ func (c *Account) Url() string {
 }
 
 func (c *Account) Balance(blockNum *big.Int) (*big.Int, error) {
-	return c.client.BalanceAt(context.Background(), c.addr, blockNum)
+	return c.BalanceContext(context.Background(), blockNum)
+}
+
+func (c *Account) BalanceContext(ctx context.Context, blockNum *big.Int) (*big.Int, error) {
+	return c.client.BalanceAt(ctx, c.addr, blockNum)
 }
 
 func (c *Account) BalanceOf(addr common.Address, blockNum *big.Int) (*big.Int, error) {
-	return c.client.BalanceAt(context.Background(), addr, blockNum)
+	return c.BalanceOfContext(context.Background(), addr, blockNum)
 }
 
-func (c *Account) Transfer(to common.Address, amount *big.Int) (common.Hash, error) {
-	signedTx, err := c.NewSignedTx(to, amount, nil)
-	if err != nil {
-		return EmptyHash, err
-	}
-	if err := c.SendTx(signedTx); err != nil {
-		return EmptyHash, err
-	}
-	if err := c.WaitTransaction(signedTx.Hash()); err != nil {
-		return EmptyHash, err
-	}
-	return signedTx.Hash(), nil
+func (c *Account) BalanceOfContext(ctx context.Context, addr common.Address, blockNum *big.Int) (*big.Int, error) {
+	return c.client.BalanceAt(ctx, addr, blockNum)
+}
+
+func (c *Account) Transfer(to common.Address, amount *big.Int) (*Receipt, error) {
+	return c.TransferContext(context.Background(), to, amount)
+}
+
+// TransferContext sends amount to the address and returns the receipt once
+// the tx is packed, the receipt is also returned if the tx reverted.
+func (c *Account) TransferContext(ctx context.Context, to common.Address, amount *big.Int) (*Receipt, error) {
+	_, receipt, err := c.signAndSendTxWithValue(ctx, nil, amount, to)
+	return receipt, err
 }
 
+// SetGasStrategy sets the strategy of txs sent by account later
+func (c *Account) SetGasStrategy(s GasStrategy) {
+	c.gas = s
+}
+
+func (c *Account) GasStrategy() GasStrategy {
+	return c.gas
+}
+
+// Nonce returns the nonce of the next tx
 func (c *Account) Nonce() uint64 {
-	c.nonceMu.RLock()
-	defer c.nonceMu.RUnlock()
-	return c.nonce
+	return c.nonces.Peek()
+}
+
+// Nonces returns the nonce manager shared by the concurrent senders of account
+func (c *Account) Nonces() *NonceManager {
+	return c.nonces
+}
+
+// ReleaseNonce releases the nonce of tx created but never sent
+func (c *Account) ReleaseNonce(nonce uint64) {
+	c.nonces.Release(nonce)
+}
+
+// ResyncNonce resyncs the nonce with the pending nonce of node
+func (c *Account) ResyncNonce(ctx context.Context) (uint64, error) {
+	return c.nonces.Resync(ctx)
+}
+
+// releaseNonce releases the nonce of tx failed to broadcast, and resyncs if
+// the nonce is out of sync.
+func (c *Account) releaseNonce(ctx context.Context, nonce uint64, err error) {
+	c.nonces.Release(nonce)
+	if !IsNonceError(err) {
+		return
+	}
+	next, rerr := c.nonces.Resync(ctx)
+	if rerr != nil {
+		log.Errorf("%s failed to resync nonce, err: %v", c.addr.Hex(), rerr)
+		return
+	}
+	log.Warnf("%s nonce %d out of sync, err: %v, resync next nonce %d", c.addr.Hex(), nonce, err, next)
 }
 
 func (c *Account) NewUnsignedTx(to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
-	nonce := c.Nonce()
-	gasPrice, err := c.client.SuggestGasPrice(context.Background())
+	return c.NewUnsignedTxContext(context.Background(), to, amount, data)
+}
+
+// NewUnsignedTxContext allocates nonce for the tx, which is committed or
+// released by SendTxContext. use ReleaseNonce if the tx won't be sent. the tx
+// type is the one in ctx set by WithTxType, or the one of account.
+func (c *Account) NewUnsignedTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
+	txType, err := c.txTypeOf(ctx)
 	if err != nil {
 		return nil, err
 	}
-
-	callMsg := ethereum.CallMsg{
-		From:     c.Addr(),
-		To:       &to,
-		Gas:      0,
-		GasPrice: gasPrice,
-		Value:    amount,
-		Data:     data,
+	if txType == types.DynamicFeeTxType {
+		return c.newDynamicFeeTx(ctx, to, amount, data)
 	}
-	gasLimit, err := c.client.EstimateGas(context.Background(), callMsg)
+
+	gasPrice, gasLimit, err := c.gasOf(ctx, to, amount, data)
 	if err != nil {
-		return nil, fmt.Errorf("estimate gas limit error: %s", err.Error())
+		return nil, err
 	}
 
+	nonce := c.nonces.Next()
 	return types.NewTx(&types.LegacyTx{
 		Nonce:    nonce,
 		To:       &to,
@@ -146,38 +209,90 @@ func (c *Account) NewUnsignedTx(to common.Address, amount *big.Int, data []byte)
 	}), nil
 }
 
+// gasOf returns the gas price and gas limit of tx decided by account strategy
+func (c *Account) gasOf(ctx context.Context, to common.Address, amount *big.Int, data []byte) (*big.Int, uint64, error) {
+	gasPrice, err := c.gas.GasPrice(ctx, c.client)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	callMsg := ethereum.CallMsg{
+		From:     c.Addr(),
+		To:       &to,
+		Gas:      0,
+		GasPrice: gasPrice,
+		Value:    amount,
+		Data:     data,
+	}
+	gasLimit, err := c.gas.GasLimit(ctx, c.client, callMsg)
+	if err != nil {
+		return nil, 0, err
+	}
+	return gasPrice, gasLimit, nil
+}
+
 func (c *Account) NewSignedTx(to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
-	unsignedTx, err := c.NewUnsignedTx(to, amount, data)
+	return c.NewSignedTxContext(context.Background(), to, amount, data)
+}
+
+func (c *Account) NewSignedTxContext(ctx context.Context, to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
+	unsignedTx, err := c.NewUnsignedTxContext(ctx, to, amount, data)
 	if err != nil {
 		return nil, err
 	}
-	return types.SignTx(unsignedTx, c.signer, c.pk)
+	signedTx, err := types.SignTx(unsignedTx, c.signer, c.pk)
+	if err != nil {
+		c.nonces.Release(unsignedTx.Nonce())
+		return nil, err
+	}
+	return signedTx, nil
 }
 
 func (c *Account) SendTx(signedTx *types.Transaction) error {
-	defer func() {
-		c.nonceMu.Lock()
-		c.nonce += 1
-		c.nonceMu.Unlock()
-	}()
+	return c.SendTxContext(context.Background(), signedTx)
+}
 
-	return c.client.SendTransaction(context.Background(), signedTx)
+// SendTxContext broadcasts the tx, its nonce is committed if node accepts it,
+// and released otherwise.
+func (c *Account) SendTxContext(ctx context.Context, signedTx *types.Transaction) error {
+	if err := c.client.SendTransaction(ctx, signedTx); err != nil {
+		c.releaseNonce(ctx, signedTx.Nonce(), err)
+		return err
+	}
+	c.nonces.Commit(signedTx.Nonce())
+	return nil
 }
 
 func (c *Account) CurrentBlockNumber() (uint64, error) {
-	return c.client.BlockNumber(context.Background())
+	return c.CurrentBlockNumberContext(context.Background())
+}
+
+func (c *Account) CurrentBlockNumberContext(ctx context.Context) (uint64, error) {
+	return c.client.BlockNumber(ctx)
 }
 
 func (c *Account) BlockHeaderByNumber(blockNumber uint64) (*types.Header, error) {
-	return c.client.HeaderByNumber(context.Background(), new(big.Int).SetUint64(blockNumber))
+	return c.BlockHeaderByNumberContext(context.Background(), blockNumber)
+}
+
+func (c *Account) BlockHeaderByNumberContext(ctx context.Context, blockNumber uint64) (*types.Header, error) {
+	return c.client.HeaderByNumber(ctx, new(big.Int).SetUint64(blockNumber))
 }
 
 func (c *Account) TxNum(blockHash common.Hash) (uint, error) {
-	return c.client.TransactionCount(context.Background(), blockHash)
+	return c.TxNumContext(context.Background(), blockHash)
+}
+
+func (c *Account) TxNumContext(ctx context.Context, blockHash common.Hash) (uint, error) {
+	return c.client.TransactionCount(ctx, blockHash)
 }
 
 func (c *Account) GetAccountAndStorageProof(contract common.Address, storageKeys []string, blockNum *big.Int) ([]byte, []byte, error) {
-	proof, err := c.client.ProofAt(context.Background(), contract, storageKeys, blockNum)
+	return c.GetAccountAndStorageProofContext(context.Background(), contract, storageKeys, blockNum)
+}
+
+func (c *Account) GetAccountAndStorageProofContext(ctx context.Context, contract common.Address, storageKeys []string, blockNum *big.Int) ([]byte, []byte, error) {
+	proof, err := c.client.ProofAt(ctx, contract, storageKeys, blockNum)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -198,11 +313,19 @@ func (c *Account) GetAccountAndStorageProof(contract common.Address, storageKeys
 }
 
 func (c *Account) StorageAt(contract common.Address, storageKey common.Hash, blockNum *big.Int) ([]byte, error) {
-	return c.client.StorageAt(context.Background(), contract, storageKey, blockNum)
+	return c.StorageAtContext(context.Background(), contract, storageKey, blockNum)
+}
+
+func (c *Account) StorageAtContext(ctx context.Context, contract common.Address, storageKey common.Hash, blockNum *big.Int) ([]byte, error) {
+	return c.client.StorageAt(ctx, contract, storageKey, blockNum)
 }
 
 func (c *Account) GetProof(contract common.Address, storageKeys []string, blockNum *big.Int) ([]byte, error) {
-	proof, err := c.client.ProofAt(context.Background(), contract, storageKeys, blockNum)
+	return c.GetProofContext(context.Background(), contract, storageKeys, blockNum)
+}
+
+func (c *Account) GetProofContext(ctx context.Context, contract common.Address, storageKeys []string, blockNum *big.Int) ([]byte, error) {
+	proof, err := c.client.ProofAt(ctx, contract, storageKeys, blockNum)
 	if err != nil {
 		return nil, err
 	}
@@ -218,86 +341,79 @@ func rlpEncodeStringList(raw []string) ([]byte, error) {
 }
 
 func (c *Account) CallContract(caller, contractAddr common.Address, payload []byte, blockNum *big.Int) ([]byte, error) {
+	return c.CallContractContext(context.Background(), caller, contractAddr, payload, blockNum)
+}
+
+func (c *Account) CallContractContext(ctx context.Context, caller, contractAddr common.Address, payload []byte, blockNum *big.Int) ([]byte, error) {
 	arg := ethereum.CallMsg{
 		From: caller,
 		To:   &contractAddr,
 		Data: payload,
 	}
 
-	return c.client.CallContract(context.Background(), arg, blockNum)
+	return c.client.CallContract(ctx, arg, blockNum)
 }
 
-func (c *Account) signAndSendTx(payload []byte, contract common.Address) (common.Hash, error) {
-	return c.signAndSendTxWithValue(payload, big.NewInt(0), contract)
+func (c *Account) signAndSendTx(ctx context.Context, payload []byte, contract common.Address) (common.Hash, *Receipt, error) {
+	return c.signAndSendTxWithValue(ctx, payload, big.NewInt(0), contract)
 }
 
-func (c *Account) signAndSendTxWithValue(payload []byte, amount *big.Int, contract common.Address) (common.Hash, error) {
-	hash := common.EmptyHash
-	tx, err := c.NewSignedTx(contract, amount, payload)
-	if tx != nil {
-		hash = tx.Hash()
-	}
+// signAndSendTxWithValue returns the hash of tx once it's signed, and the
+// receipt once it's packed.
+func (c *Account) signAndSendTxWithValue(ctx context.Context, payload []byte, amount *big.Int, contract common.Address) (common.Hash, *Receipt, error) {
+	tx, err := c.NewSignedTxContext(ctx, contract, amount, payload)
 	if err != nil {
-		return hash, fmt.Errorf("sign tx failed, err: %v", err)
+		return EmptyHash, nil, fmt.Errorf("sign tx failed, err: %v", err)
 	}
 
-	if err := c.SendTx(tx); err != nil {
-		return hash, err
-	}
-	if err := c.WaitTransaction(tx.Hash()); err != nil {
-		return hash, err
+	hash := tx.Hash()
+	if err := c.SendTxContext(ctx, tx); err != nil {
+		return hash, nil, err
 	}
-	return hash, nil
+	receipt, err := c.WaitTransactionContext(ctx, hash)
+	return hash, receipt, err
 }
 
 func (c *Account) SendTransaction(contractAddr common.Address, payload []byte) (common.Hash, error) {
-	addr := c.Addr()
-
-	nonce := c.GetNonce(addr.Hex())
-	if c.nonce < nonce {
-		c.nonce = nonce
-	}
-	log.Debugf("%s current nonce %d, valid nonce %d", addr.Hex(), c.nonce, nonce)
-	tx := types.NewTransaction(
-		c.nonce,
-		contractAddr,
-		big.NewInt(0),
-		gasLimit,
-		big.NewInt(2000000000),
-		payload,
-	)
-	hash := tx.Hash()
+	return c.SendTransactionContext(context.Background(), contractAddr, payload)
+}
 
-	signedTx, err := c.SignTransaction(tx)
+// SendTransactionContext signs and broadcasts the tx calling contract, the tx
+// type follows the account and WithTxType in ctx. it returns the signed tx hash.
+func (c *Account) SendTransactionContext(ctx context.Context, contractAddr common.Address, payload []byte) (common.Hash, error) {
+	tx, err := c.NewSignedTxContext(ctx, contractAddr, big.NewInt(0), payload)
 	if err != nil {
-		return hash, err
+		return EmptyHash, err
 	}
-	c.nonce += 1
-	return c.SendRawTransaction(hash, signedTx)
+	log.Debugf("%s current nonce %d", c.Addr().Hex(), tx.Nonce())
+	if err := c.SendTxContext(ctx, tx); err != nil {
+		return tx.Hash(), err
+	}
+	return tx.Hash(), nil
 }
 
+// SignTransaction signs tx with the account signer, and returns the hex of
+// its binary encoding which is accepted by eth_sendRawTransaction.
 func (c *Account) SignTransaction(tx *types.Transaction) (string, error) {
-
-	signer := types.EIP155Signer{}
-	signedTx, err := types.SignTx(
-		tx,
-		signer,
-		c.pk,
-	)
+	signedTx, err := types.SignTx(tx, c.signer, c.pk)
 	if err != nil {
 		return "", fmt.Errorf("failed to sign tx: [%v]", err)
 	}
 
-	bz, err := rlp.EncodeToBytes(signedTx)
+	bz, err := signedTx.MarshalBinary()
 	if err != nil {
-		return "", fmt.Errorf("failed to rlp encode bytes: [%v]", err)
+		return "", fmt.Errorf("failed to encode tx: [%v]", err)
 	}
-	return "0x" + hex.EncodeToString(bz), nil
+	return hexutil.Encode(bz), nil
 }
 
 func (c *Account) SendRawTransaction(hash common.Hash, signedTx string) (common.Hash, error) {
+	return c.SendRawTransactionContext(context.Background(), hash, signedTx)
+}
+
+func (c *Account) SendRawTransactionContext(ctx context.Context, hash common.Hash, signedTx string) (common.Hash, error) {
 	var result common.Hash
-	if err := c.rpcClient.Call(&result, "eth_sendRawTransaction", signedTx); err != nil {
+	if err := c.rpcClient.CallContext(ctx, &result, "eth_sendRawTransaction", signedTx); err != nil {
 		return hash, fmt.Errorf("failed to send raw transaction: [%v]", err)
 	}
 
@@ -305,53 +421,72 @@ func (c *Account) SendRawTransaction(hash common.Hash, signedTx string) (common.
 }
 
 func (c *Account) SendTransactionAndDumpEvent(contract common.Address, payload []byte) error {
-	hash, err := c.SendTransaction(contract, payload)
+	return c.SendTransactionAndDumpEventContext(context.Background(), contract, payload)
+}
+
+// SendTransactionAndDumpEventContext sends the transaction and dumps its
+// event logs once it's packed.
+func (c *Account) SendTransactionAndDumpEventContext(ctx context.Context, contract common.Address, payload []byte) error {
+	hash, err := c.SendTransactionContext(ctx, contract, payload)
 	if err != nil {
 		return err
 	}
-	time.Sleep(2)
-	return c.DumpEventLog(hash)
+	_, err = c.WaitTransactionContext(ctx, hash)
+	return err
 }
 
-func (c *Account) WaitTransaction(hash common.Hash) error {
-	for {
-		time.Sleep(time.Second * 1)
-		_, ispending, err := c.client.TransactionByHash(context.Background(), hash)
-		if err != nil {
-			log.Errorf("failed to call TransactionByHash: %v", err)
-			continue
-		}
-		if ispending == true {
-			continue
-		}
+func (c *Account) WaitTransaction(hash common.Hash) (*Receipt, error) {
+	return c.WaitTransactionContext(context.Background(), hash)
+}
 
-		if err := c.DumpEventLog(hash); err != nil {
-			return err
-		}
-		break
+// WaitTransactionContext waits the receipt with the wait timeout and
+// confirmations of account, and dumps its event logs.
+func (c *Account) WaitTransactionContext(ctx context.Context, hash common.Hash) (*Receipt, error) {
+	receipt, err := c.WaitReceipt(ctx, hash, c.waitTimeout, c.confirmations)
+	if err != nil {
+		return receipt, err
 	}
-	return nil
+	dumpEventLog(receipt.Receipt)
+	log.Infof("txhash %s, gas used %d, effective gas price %v", hash.Hex(), receipt.GasUsed, receipt.EffectiveGasPrice)
+	return receipt, nil
 }
 
 func (c *Account) GetNonce(address string) uint64 {
+	nonce, err := c.GetNonceContext(context.Background(), address)
+	if err != nil {
+		panic(err)
+	}
+	return nonce
+}
+
+// GetNonceContext returns the nonce of address at the latest block
+func (c *Account) GetNonceContext(ctx context.Context, address string) (uint64, error) {
 	var raw string
 
-	if err := c.rpcClient.Call(
+	if err := c.rpcClient.CallContext(
+		ctx,
 		&raw,
 		"eth_getTransactionCount",
 		address,
 		"latest",
 	); err != nil {
-		panic(fmt.Errorf("failed to get nonce: [%v]", err))
+		return 0, fmt.Errorf("failed to get nonce: [%v]", err)
 	}
 
 	without0xStr := strings.Replace(raw, "0x", "", -1)
-	bigNonce, _ := new(big.Int).SetString(without0xStr, 16)
-	return bigNonce.Uint64()
+	bigNonce, ok := new(big.Int).SetString(without0xStr, 16)
+	if !ok {
+		return 0, fmt.Errorf("invalid nonce %s", raw)
+	}
+	return bigNonce.Uint64(), nil
 }
 
 func (c *Account) DumpEventLog(hash common.Hash) error {
-	raw, err := c.GetReceipt(hash)
+	return c.DumpEventLogContext(context.Background(), hash)
+}
+
+func (c *Account) DumpEventLogContext(ctx context.Context, hash common.Hash) error {
+	raw, err := c.GetReceiptContext(ctx, hash)
 	if err != nil {
 		return fmt.Errorf("faild to get receipt %s", hash.Hex())
 	}
@@ -360,34 +495,29 @@ func (c *Account) DumpEventLog(hash common.Hash) error {
 		return fmt.Errorf("receipt failed %s", hash.Hex())
 	}
 
-	log.Infof("txhash %s, block height %d", hash.Hex(), raw.BlockNumber.Uint64())
-	for _, event := range raw.Logs {
+	dumpEventLog(raw)
+	return nil
+}
+
+func dumpEventLog(receipt *types.Receipt) {
+	log.Infof("txhash %s, block height %d", receipt.TxHash.Hex(), receipt.BlockNumber.Uint64())
+	for _, event := range receipt.Logs {
 		log.Infof("eventlog addr %s", event.Address.Hex())
 		log.Infof("eventlog data %s", hexutil.Encode(event.Data))
 		for i, topic := range event.Topics {
 			log.Infof("eventlog topic[%d] %s", i, topic.String())
 		}
 	}
-	return nil
 }
 
 func (c *Account) GetReceipt(hash common.Hash) (*types.Receipt, error) {
+	return c.GetReceiptContext(context.Background(), hash)
+}
+
+func (c *Account) GetReceiptContext(ctx context.Context, hash common.Hash) (*types.Receipt, error) {
 	raw := &types.Receipt{}
-	if err := c.rpcClient.Call(raw, "eth_getTransactionReceipt", hash.Hex()); err != nil {
+	if err := c.rpcClient.CallContext(ctx, raw, "eth_getTransactionReceipt", hash.Hex()); err != nil {
 		return nil, err
 	}
 	return raw, nil
 }
-
-func AddGasPrice(inc uint64) {
-	added := new(big.Int).SetUint64(inc)
-	gasPrice = new(big.Int).Add(gasPrice, added)
-}
-
-func DefaultGasPrice() *big.Int {
-	return gasPrice
-}
-
-func DefaultGasLimit() *big.Int {
-	return new(big.Int).SetUint64(gasLimit)
-}
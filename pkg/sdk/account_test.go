@@ -51,11 +51,47 @@ func TestTransfer(t *testing.T) {
 	to := common.HexToAddress("0x67CDE763bD045B14898d8B044F8afC8695ae8608")
 	amount := 1000000000
 	value := new(big.Int).Mul(testEth1, new(big.Int).SetUint64(uint64(amount)))
-	hash, err := master.Transfer(to, value)
+	receipt, err := master.Transfer(to, value)
 	if err != nil {
 		t.Fatal(err)
 	}
-	t.Logf("master %s transfer %v to %s, hash %s", master.Addr(), amount, to.Hex(), hash.Hex())
+	t.Logf("master %s transfer %v to %s, hash %s, block %d", master.Addr(), amount, to.Hex(), receipt.TxHash.Hex(), receipt.BlockNumber)
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestDynamicFeeTransfer
+func TestDynamicFeeTransfer(t *testing.T) {
+	to := common.HexToAddress("0x67CDE763bD045B14898d8B044F8afC8695ae8608")
+	ctx := WithTxType(context.Background(), types.DynamicFeeTxType)
+	receipt, err := master.TransferContext(ctx, to, testEth1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.NotNil(t, receipt.EffectiveGasPrice)
+	t.Logf("hash %s, gas used %d, effective gas price %v", receipt.TxHash.Hex(), receipt.GasUsed, receipt.EffectiveGasPrice)
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestSignTransaction
+func TestSignTransaction(t *testing.T) {
+	pk, _ := crypto.GenerateKey()
+	chainID := new(big.Int).SetUint64(testChainID)
+	acc := &Account{chainID: chainID, signer: types.NewLondonSigner(chainID), pk: pk, addr: crypto.PubkeyToAddress(pk.PublicKey)}
+	to := common.HexToAddress("0x67CDE763bD045B14898d8B044F8afC8695ae8608")
+
+	for _, unsigned := range []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: testEth1, Gas: 21000, GasPrice: big.NewInt(1)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 2, To: &to, Value: testEth1, Gas: 21000, GasTipCap: big.NewInt(1), GasFeeCap: big.NewInt(2)}),
+	} {
+		raw, err := acc.SignTransaction(unsigned)
+		assert.NoError(t, err)
+
+		tx := new(types.Transaction)
+		assert.NoError(t, tx.UnmarshalBinary(common.FromHex(raw)))
+		assert.Equal(t, unsigned.Type(), tx.Type())
+		assert.Equal(t, chainID, tx.ChainId())
+		sender, err := types.Sender(acc.signer, tx)
+		assert.NoError(t, err)
+		assert.Equal(t, acc.addr, sender)
+	}
 }
 
 func TestGetBlock(t *testing.T) {
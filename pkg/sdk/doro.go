@@ -20,6 +20,7 @@ package sdk
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"strings"
 
@@ -29,9 +30,14 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/contracts/native/utils"
+	"github.com/ethereum/go-ethereum/core/types"
 )
 
 func (c *Account) SetDoro(contract common.Address, num uint64) (common.Hash, error) {
+	return c.SetDoroContext(context.Background(), contract, num)
+}
+
+func (c *Account) SetDoroContext(ctx context.Context, contract common.Address, num uint64) (common.Hash, error) {
 	ab, err := abi.JSON(strings.NewReader(doro.DoroABI))
 	if err != nil {
 		return common.EmptyHash, err
@@ -40,25 +46,41 @@ func (c *Account) SetDoro(contract common.Address, num uint64) (common.Hash, err
 	if err != nil {
 		return common.EmptyHash, err
 	}
-	return c.signAndSendTx(payload, contract)
+	hash, _, err := c.signAndSendTx(ctx, payload, contract)
+	return hash, err
 }
 
-func (c *Account) makeAuthWithoutGasLimit() (*bind.TransactOpts, error) {
-	fromAddress := c.Addr()
-	nonce, err := c.client.PendingNonceAt(context.Background(), fromAddress)
+// makeAuthWithoutGasLimit takes the nonce of auth from the nonce manager,
+// settle must be called with the error of sending tx with auth, so that the
+// nonce is committed on success and released otherwise. auth signs with the
+// chain id and sets fee caps instead of gas price for dynamic fee tx type.
+func (c *Account) makeAuthWithoutGasLimit(ctx context.Context) (auth *bind.TransactOpts, settle func(err error), err error) {
+	txType, err := c.txTypeOf(ctx)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	if auth, err = bind.NewKeyedTransactorWithChainID(c.pk, c.chainID); err != nil {
+		return nil, nil, err
+	}
+	if txType == types.DynamicFeeTxType {
+		if auth.GasTipCap, auth.GasFeeCap, err = c.fees.Fees(ctx, c); err != nil {
+			return nil, nil, fmt.Errorf("estimate fees error: %v", err)
+		}
+	} else if auth.GasPrice, err = c.gas.GasPrice(ctx, c.client); err != nil {
+		return nil, nil, err
 	}
 
-	gasPrice, err := c.client.SuggestGasPrice(context.Background())
-	if err != nil {
-		return nil, err
+	nonce := c.nonces.Next()
+	settle = func(err error) {
+		if err != nil {
+			c.releaseNonce(ctx, nonce, err)
+			return
+		}
+		c.nonces.Commit(nonce)
 	}
 
-	auth := bind.NewKeyedTransactor(c.pk)
-	auth.Nonce = big.NewInt(int64(nonce))
+	auth.Nonce = new(big.Int).SetUint64(nonce)
 	auth.Value = big.NewInt(int64(0)) // in wei
 	//auth.GasLimit = uint64(0) // in units
-	auth.GasPrice = gasPrice.Mul(gasPrice, big.NewInt(1))
-	return auth, nil
+	return auth, settle, nil
 }
@@ -19,10 +19,16 @@
 package sdk
 
 import (
+	"context"
+	"errors"
+	"math/big"
 	"testing"
 
 	"github.com/dylenfu/zion-tool/pkg/go_abi/doro"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestDoro1(t *testing.T) {
@@ -43,16 +49,17 @@ func TestDoro2(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
-	auth, err := acc.makeAuthWithoutGasLimit()
+	auth, settle, err := acc.makeAuthWithoutGasLimit(context.Background())
 	if err != nil {
 		t.Fatal(err)
 	}
 
 	tx, err := instance.SetDoro(auth, num)
+	settle(err)
 	if err != nil {
 		t.Fatal(err)
 	}
-	if err := acc.WaitTransaction(tx.Hash()); err != nil {
+	if _, err := acc.WaitTransaction(tx.Hash()); err != nil {
 		t.Fatal(err)
 	}
 	got, err := instance.Data(nil)
@@ -62,3 +69,53 @@ func TestDoro2(t *testing.T) {
 
 	t.Logf("expect num %d, got %d", num, got)
 }
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestDoroAuthNonce
+func TestDoroAuthNonce(t *testing.T) {
+	remote := uint64(5)
+	pk, _ := crypto.GenerateKey()
+	chainID := new(big.Int).SetUint64(testChainID)
+	acc := &Account{chainID: chainID, signer: types.NewLondonSigner(chainID), pk: pk, addr: crypto.PubkeyToAddress(pk.PublicKey),
+		nonces: NewNonceManager(fixedNonce(&remote)), gas: FixedGas(big.NewInt(1), 0), fees: &fixedFees{tip: big.NewInt(2), fee: big.NewInt(3)}}
+	_, err := acc.nonces.Resync(context.Background())
+	assert.NoError(t, err)
+
+	auth, settle, err := acc.makeAuthWithoutGasLimit(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), auth.Nonce.Uint64())
+	settle(errors.New("execution reverted"))
+
+	// released nonce is reused, and the committed one is not
+	auth, settle, err = acc.makeAuthWithoutGasLimit(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), auth.Nonce.Uint64())
+	settle(nil)
+
+	auth, settle, err = acc.makeAuthWithoutGasLimit(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(6), auth.Nonce.Uint64())
+	assert.Equal(t, int64(1), auth.GasPrice.Int64())
+	settle(nil)
+
+	// auth signs with chain id, and sets fee caps for dynamic fee tx
+	auth, _, err = acc.makeAuthWithoutGasLimit(WithTxType(context.Background(), types.DynamicFeeTxType))
+	assert.NoError(t, err)
+	assert.Nil(t, auth.GasPrice)
+	assert.Equal(t, int64(2), auth.GasTipCap.Int64())
+	assert.Equal(t, int64(3), auth.GasFeeCap.Int64())
+
+	to := common.HexToAddress("0x73b0727DA810d0be51D74E83655398fA6DC828aa")
+	tx, err := auth.Signer(acc.addr, types.NewTx(&types.DynamicFeeTx{ChainID: chainID, Nonce: 7, To: &to, Gas: 21000, GasTipCap: auth.GasTipCap, GasFeeCap: auth.GasFeeCap}))
+	assert.NoError(t, err)
+	sender, err := types.Sender(acc.signer, tx)
+	assert.NoError(t, err)
+	assert.Equal(t, acc.addr, sender)
+}
+
+type fixedFees struct {
+	tip, fee *big.Int
+}
+
+func (f *fixedFees) Fees(ctx context.Context, oracle FeeOracle) (*big.Int, *big.Int, error) {
+	return f.tip, f.fee, nil
+}
@@ -0,0 +1,170 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+type txTypeKey struct{}
+
+// WithTxType overrides the tx type of account for the txs created with ctx,
+// e.g: types.DynamicFeeTxType
+func WithTxType(ctx context.Context, txType uint8) context.Context {
+	return context.WithValue(ctx, txTypeKey{}, txType)
+}
+
+func checkTxType(txType uint8) error {
+	if txType != types.LegacyTxType && txType != types.DynamicFeeTxType {
+		return fmt.Errorf("tx type %d not supported", txType)
+	}
+	return nil
+}
+
+// SetTxType sets the type of txs created by account, legacy by default
+func (c *Account) SetTxType(txType uint8) error {
+	if err := checkTxType(txType); err != nil {
+		return err
+	}
+	c.txType = txType
+	return nil
+}
+
+func (c *Account) TxType() uint8 {
+	return c.txType
+}
+
+// txTypeOf returns the tx type in ctx, and the type of account if absent
+func (c *Account) txTypeOf(ctx context.Context) (uint8, error) {
+	if txType, ok := ctx.Value(txTypeKey{}).(uint8); ok {
+		return txType, checkTxType(txType)
+	}
+	return c.txType, nil
+}
+
+// SetFeeEstimator sets the estimator of dynamic fee txs sent by account later
+func (c *Account) SetFeeEstimator(e FeeEstimator) {
+	c.fees = e
+}
+
+func (c *Account) FeeEstimator() FeeEstimator {
+	return c.fees
+}
+
+// FeeHistory returns the base fees and the rewards at percentiles of txs in
+// the recent blocks.
+func (c *Account) FeeHistory(ctx context.Context, blocks uint64, percentiles []float64) (*FeeHistory, error) {
+	var raw struct {
+		OldestBlock  *hexutil.Big     `json:"oldestBlock"`
+		Reward       [][]*hexutil.Big `json:"reward"`
+		BaseFee      []*hexutil.Big   `json:"baseFeePerGas"`
+		GasUsedRatio []float64        `json:"gasUsedRatio"`
+	}
+	if err := c.rpcClient.CallContext(ctx, &raw, "eth_feeHistory", hexutil.Uint64(blocks), "latest", percentiles); err != nil {
+		return nil, err
+	}
+
+	history := &FeeHistory{
+		OldestBlock:  (*big.Int)(raw.OldestBlock),
+		Reward:       make([][]*big.Int, 0, len(raw.Reward)),
+		BaseFee:      make([]*big.Int, 0, len(raw.BaseFee)),
+		GasUsedRatio: raw.GasUsedRatio,
+	}
+	for _, rewards := range raw.Reward {
+		list := make([]*big.Int, 0, len(rewards))
+		for _, reward := range rewards {
+			list = append(list, (*big.Int)(reward))
+		}
+		history.Reward = append(history.Reward, list)
+	}
+	for _, baseFee := range raw.BaseFee {
+		history.BaseFee = append(history.BaseFee, (*big.Int)(baseFee))
+	}
+	return history, nil
+}
+
+// newDynamicFeeTx builds the EIP-1559 tx with fees decided by the account
+// estimator, and the gas limit by the account gas strategy.
+func (c *Account) newDynamicFeeTx(ctx context.Context, to common.Address, amount *big.Int, data []byte) (*types.Transaction, error) {
+	tipCap, feeCap, err := c.fees.Fees(ctx, c)
+	if err != nil {
+		return nil, fmt.Errorf("estimate fees error: %v", err)
+	}
+
+	callMsg := ethereum.CallMsg{
+		From:      c.Addr(),
+		To:        &to,
+		GasFeeCap: feeCap,
+		GasTipCap: tipCap,
+		Value:     amount,
+		Data:      data,
+	}
+	gasLimit, err := c.gas.GasLimit(ctx, c.client, callMsg)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := c.nonces.Next()
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   c.chainID,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       gasLimit,
+		To:        &to,
+		Value:     amount,
+		Data:      data,
+	}), nil
+}
+
+// Receipt is the tx receipt along with the gas price actually paid, which is
+// the gas price of legacy tx, or min(feeCap, baseFee + tipCap) of dynamic fee tx.
+type Receipt struct {
+	*types.Receipt
+	EffectiveGasPrice *big.Int
+}
+
+func (c *Account) newReceipt(ctx context.Context, receipt *types.Receipt) (*Receipt, error) {
+	tx, _, err := c.client.TransactionByHash(ctx, receipt.TxHash)
+	if err != nil {
+		return nil, err
+	}
+	if tx.Type() != types.DynamicFeeTxType {
+		return &Receipt{Receipt: receipt, EffectiveGasPrice: tx.GasPrice()}, nil
+	}
+
+	header, err := c.client.HeaderByHash(ctx, receipt.BlockHash)
+	if err != nil {
+		return nil, err
+	}
+	if header.BaseFee == nil {
+		return nil, ErrNoBaseFee
+	}
+	return &Receipt{
+		Receipt:           receipt,
+		EffectiveGasPrice: effectiveGasPrice(tx.GasTipCap(), tx.GasFeeCap(), header.BaseFee),
+	}, nil
+}
@@ -0,0 +1,139 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"sync"
+)
+
+// ErrNoBaseFee is returned by fee estimator if London rules not activated
+var ErrNoBaseFee = errors.New("base fee not found, london rules not activated")
+
+// DefaultPriorityFee is the tip used if no tx packed in the recent blocks
+var DefaultPriorityFee = big.NewInt(1000000000)
+
+// FeeHistory is the result of eth_feeHistory, BaseFee has one more item than
+// the blocks queried which is the base fee of the next block.
+type FeeHistory struct {
+	OldestBlock  *big.Int
+	Reward       [][]*big.Int
+	BaseFee      []*big.Int
+	GasUsedRatio []float64
+}
+
+// FeeOracle is the node api used by fee estimators, it's implemented by Account
+type FeeOracle interface {
+	FeeHistory(ctx context.Context, blocks uint64, percentiles []float64) (*FeeHistory, error)
+}
+
+// FeeEstimator decides the priority fee (tip cap) and max fee (fee cap) of
+// dynamic fee txs.
+type FeeEstimator interface {
+	Fees(ctx context.Context, oracle FeeOracle) (tipCap *big.Int, feeCap *big.Int, err error)
+}
+
+var (
+	defaultFeeMu sync.RWMutex
+	defaultFee   FeeEstimator = NewFeeHistoryEstimator()
+)
+
+// DefaultFeeEstimator returns the estimator of accounts created later
+func DefaultFeeEstimator() FeeEstimator {
+	defaultFeeMu.RLock()
+	defer defaultFeeMu.RUnlock()
+	return defaultFee
+}
+
+// SetDefaultFeeEstimator sets the estimator of accounts created later, the
+// existing accounts are not affected.
+func SetDefaultFeeEstimator(e FeeEstimator) {
+	defaultFeeMu.Lock()
+	defer defaultFeeMu.Unlock()
+	defaultFee = e
+}
+
+// FeeHistoryEstimator takes the median of the rewards paid in recent blocks
+// as tip, and leaves room for the base fee increasing in the next blocks.
+//
+//	tipCap = median(reward at Percentile of non-empty blocks), at least MinTip
+//	feeCap = base fee of next block * BaseFeeMultiplier + tipCap
+type FeeHistoryEstimator struct {
+	Blocks            uint64
+	Percentile        float64
+	BaseFeeMultiplier float64
+	MinTip            *big.Int
+}
+
+func NewFeeHistoryEstimator() *FeeHistoryEstimator {
+	return &FeeHistoryEstimator{
+		Blocks:            10,
+		Percentile:        50,
+		BaseFeeMultiplier: 2,
+	}
+}
+
+func (e *FeeHistoryEstimator) Fees(ctx context.Context, oracle FeeOracle) (*big.Int, *big.Int, error) {
+	history, err := oracle.FeeHistory(ctx, e.Blocks, []float64{e.Percentile})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, nil, ErrNoBaseFee
+	}
+	baseFee := history.BaseFee[len(history.BaseFee)-1]
+	if baseFee == nil || baseFee.Sign() == 0 {
+		return nil, nil, ErrNoBaseFee
+	}
+
+	tips := make([]*big.Int, 0, len(history.Reward))
+	for i, reward := range history.Reward {
+		// empty blocks report zero rewards
+		if i < len(history.GasUsedRatio) && history.GasUsedRatio[i] == 0 {
+			continue
+		}
+		if len(reward) > 0 && reward[0] != nil {
+			tips = append(tips, reward[0])
+		}
+	}
+	tipCap := new(big.Int).Set(DefaultPriorityFee)
+	if len(tips) > 0 {
+		sort.Slice(tips, func(i, j int) bool { return tips[i].Cmp(tips[j]) < 0 })
+		tipCap = new(big.Int).Set(tips[len(tips)/2])
+	}
+	if e.MinTip != nil && tipCap.Cmp(e.MinTip) < 0 {
+		tipCap = new(big.Int).Set(e.MinTip)
+	}
+
+	feeCap := new(big.Int).Add(mulBig(baseFee, e.BaseFeeMultiplier), tipCap)
+	return tipCap, feeCap, nil
+}
+
+// effectiveGasPrice returns the gas price paid per gas by dynamic fee tx
+// packed in the block with baseFee: min(feeCap, baseFee + tipCap).
+func effectiveGasPrice(tipCap, feeCap, baseFee *big.Int) *big.Int {
+	price := new(big.Int).Add(baseFee, tipCap)
+	if price.Cmp(feeCap) > 0 {
+		return new(big.Int).Set(feeCap)
+	}
+	return price
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeFeeOracle struct {
+	history *FeeHistory
+}
+
+func (o *fakeFeeOracle) FeeHistory(ctx context.Context, blocks uint64, percentiles []float64) (*FeeHistory, error) {
+	return o.history, nil
+}
+
+func bigs(list ...int64) []*big.Int {
+	res := make([]*big.Int, 0, len(list))
+	for _, n := range list {
+		res = append(res, big.NewInt(n))
+	}
+	return res
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestFeeHistoryEstimator
+func TestFeeHistoryEstimator(t *testing.T) {
+	ctx := context.Background()
+	oracle := &fakeFeeOracle{history: &FeeHistory{
+		Reward:       [][]*big.Int{bigs(30), bigs(0), bigs(10), bigs(20)},
+		BaseFee:      bigs(100, 110, 120, 130, 140),
+		GasUsedRatio: []float64{0.5, 0, 0.8, 0.3},
+	}}
+
+	// the empty block is skipped, median of 30, 10, 20
+	e := NewFeeHistoryEstimator()
+	tipCap, feeCap, err := e.Fees(ctx, oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), tipCap.Int64())
+	assert.Equal(t, int64(140*2+20), feeCap.Int64())
+
+	e.MinTip = big.NewInt(50)
+	tipCap, feeCap, err = e.Fees(ctx, oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(50), tipCap.Int64())
+	assert.Equal(t, int64(140*2+50), feeCap.Int64())
+
+	// no tx in recent blocks
+	oracle.history.GasUsedRatio = []float64{0, 0, 0, 0}
+	tipCap, _, err = NewFeeHistoryEstimator().Fees(ctx, oracle)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultPriorityFee, tipCap)
+
+	// london not activated
+	oracle.history.BaseFee = bigs(0, 0, 0, 0, 0)
+	_, _, err = e.Fees(ctx, oracle)
+	assert.Equal(t, ErrNoBaseFee, err)
+}
+
+func TestEffectiveGasPrice(t *testing.T) {
+	assert.Equal(t, int64(120), effectiveGasPrice(big.NewInt(20), big.NewInt(300), big.NewInt(100)).Int64())
+	assert.Equal(t, int64(110), effectiveGasPrice(big.NewInt(20), big.NewInt(110), big.NewInt(100)).Int64())
+}
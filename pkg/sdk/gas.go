@@ -0,0 +1,189 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"sync"
+
+	"github.com/ethereum/go-ethereum"
+)
+
+// GasOracle is the node api used by gas strategies, it's implemented by ethclient
+type GasOracle interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error)
+}
+
+// GasStrategy decides the gas price and gas limit of txs sent by account,
+// msg is the tx to be sent with the gas price decided.
+type GasStrategy interface {
+	GasPrice(ctx context.Context, oracle GasOracle) (*big.Int, error)
+	GasLimit(ctx context.Context, oracle GasOracle, msg ethereum.CallMsg) (uint64, error)
+}
+
+var (
+	defaultGasMu sync.RWMutex
+	defaultGas   GasStrategy = SuggestedGas()
+)
+
+// DefaultGasStrategy returns the strategy of accounts created later
+func DefaultGasStrategy() GasStrategy {
+	defaultGasMu.RLock()
+	defer defaultGasMu.RUnlock()
+	return defaultGas
+}
+
+// SetDefaultGasStrategy sets the strategy of accounts created later, the
+// existing accounts are not affected.
+func SetDefaultGasStrategy(s GasStrategy) {
+	defaultGasMu.Lock()
+	defer defaultGasMu.Unlock()
+	defaultGas = s
+}
+
+type suggestedGas struct{}
+
+// SuggestedGas uses the gas price suggested by node and the estimated gas limit
+func SuggestedGas() GasStrategy {
+	return suggestedGas{}
+}
+
+func (suggestedGas) GasPrice(ctx context.Context, oracle GasOracle) (*big.Int, error) {
+	return oracle.SuggestGasPrice(ctx)
+}
+
+func (suggestedGas) GasLimit(ctx context.Context, oracle GasOracle, msg ethereum.CallMsg) (uint64, error) {
+	limit, err := oracle.EstimateGas(ctx, msg)
+	if err != nil {
+		return 0, fmt.Errorf("estimate gas limit error: %s", err.Error())
+	}
+	return limit, nil
+}
+
+type fixedGas struct {
+	suggestedGas
+	price *big.Int
+	limit uint64
+}
+
+// FixedGas uses the fixed gas price and gas limit, limit 0 means estimated
+func FixedGas(price *big.Int, limit uint64) GasStrategy {
+	return &fixedGas{price: new(big.Int).Set(price), limit: limit}
+}
+
+func (s *fixedGas) GasPrice(ctx context.Context, oracle GasOracle) (*big.Int, error) {
+	return new(big.Int).Set(s.price), nil
+}
+
+func (s *fixedGas) GasLimit(ctx context.Context, oracle GasOracle, msg ethereum.CallMsg) (uint64, error) {
+	if s.limit == 0 {
+		return s.suggestedGas.GasLimit(ctx, oracle, msg)
+	}
+	return s.limit, nil
+}
+
+type multipliedGasPrice struct {
+	GasStrategy
+	multiplier float64
+}
+
+// MultiplyGasPrice multiplies the gas price of base, e.g: 1.2 for 20% more
+// than the node suggestion.
+func MultiplyGasPrice(base GasStrategy, multiplier float64) GasStrategy {
+	return &multipliedGasPrice{GasStrategy: base, multiplier: multiplier}
+}
+
+func (s *multipliedGasPrice) GasPrice(ctx context.Context, oracle GasOracle) (*big.Int, error) {
+	price, err := s.GasStrategy.GasPrice(ctx, oracle)
+	if err != nil {
+		return nil, err
+	}
+	return mulBig(price, s.multiplier), nil
+}
+
+type increasedGasPrice struct {
+	GasStrategy
+	inc *big.Int
+}
+
+// IncreaseGasPrice adds inc wei to the gas price of base
+func IncreaseGasPrice(base GasStrategy, inc *big.Int) GasStrategy {
+	return &increasedGasPrice{GasStrategy: base, inc: new(big.Int).Set(inc)}
+}
+
+func (s *increasedGasPrice) GasPrice(ctx context.Context, oracle GasOracle) (*big.Int, error) {
+	price, err := s.GasStrategy.GasPrice(ctx, oracle)
+	if err != nil {
+		return nil, err
+	}
+	return new(big.Int).Add(price, s.inc), nil
+}
+
+type cappedGasPrice struct {
+	GasStrategy
+	max *big.Int
+}
+
+// CapGasPrice limits the gas price of base to max
+func CapGasPrice(base GasStrategy, max *big.Int) GasStrategy {
+	return &cappedGasPrice{GasStrategy: base, max: new(big.Int).Set(max)}
+}
+
+func (s *cappedGasPrice) GasPrice(ctx context.Context, oracle GasOracle) (*big.Int, error) {
+	price, err := s.GasStrategy.GasPrice(ctx, oracle)
+	if err != nil {
+		return nil, err
+	}
+	if price.Cmp(s.max) > 0 {
+		return new(big.Int).Set(s.max), nil
+	}
+	return price, nil
+}
+
+type marginGasLimit struct {
+	GasStrategy
+	margin float64
+}
+
+// EstimateMargin multiplies the gas limit of base by the safety margin, e.g:
+// 1.5 for the txs whose gas used depends on the state changed after estimated.
+func EstimateMargin(base GasStrategy, margin float64) GasStrategy {
+	return &marginGasLimit{GasStrategy: base, margin: margin}
+}
+
+func (s *marginGasLimit) GasLimit(ctx context.Context, oracle GasOracle, msg ethereum.CallMsg) (uint64, error) {
+	limit, err := s.GasStrategy.GasLimit(ctx, oracle, msg)
+	if err != nil {
+		return 0, err
+	}
+	return mulBig(new(big.Int).SetUint64(limit), s.margin).Uint64(), nil
+}
+
+// mulBig returns x * f rounded up, so that the multiplied gas is never less
+// than expected for the float error.
+func mulBig(x *big.Int, f float64) *big.Int {
+	res, acc := new(big.Float).Mul(new(big.Float).SetInt(x), big.NewFloat(f)).Int(nil)
+	if acc == big.Below {
+		res.Add(res, big.NewInt(1))
+	}
+	return res
+}
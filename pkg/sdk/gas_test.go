@@ -0,0 +1,72 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeOracle struct {
+	price    int64
+	estimate uint64
+}
+
+func (o *fakeOracle) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return big.NewInt(o.price), nil
+}
+
+func (o *fakeOracle) EstimateGas(ctx context.Context, msg ethereum.CallMsg) (uint64, error) {
+	return o.estimate, nil
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestGasStrategy
+func TestGasStrategy(t *testing.T) {
+	ctx := context.Background()
+	oracle := &fakeOracle{price: 1000, estimate: 21000}
+
+	cases := []struct {
+		name     string
+		strategy GasStrategy
+		price    int64
+		limit    uint64
+	}{
+		{"suggested", SuggestedGas(), 1000, 21000},
+		{"fixed", FixedGas(big.NewInt(2000), 210000), 2000, 210000},
+		{"fixed price with estimated limit", FixedGas(big.NewInt(2000), 0), 2000, 21000},
+		{"multiplied", MultiplyGasPrice(SuggestedGas(), 1.5), 1500, 21000},
+		{"increased", IncreaseGasPrice(SuggestedGas(), big.NewInt(7)), 1007, 21000},
+		{"capped", CapGasPrice(MultiplyGasPrice(SuggestedGas(), 3), big.NewInt(2500)), 2500, 21000},
+		{"under cap", CapGasPrice(SuggestedGas(), big.NewInt(2500)), 1000, 21000},
+		{"margin", EstimateMargin(SuggestedGas(), 1.2), 1000, 25200},
+	}
+	for _, c := range cases {
+		price, err := c.strategy.GasPrice(ctx, oracle)
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.price, price.Int64(), c.name)
+
+		limit, err := c.strategy.GasLimit(ctx, oracle, ethereum.CallMsg{})
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.limit, limit, c.name)
+	}
+}
@@ -19,6 +19,7 @@
 package sdk
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
@@ -37,11 +38,15 @@ func init() {
 }
 
 func (c *Account) Epoch() (*nm.EpochInfo, error) {
+	return c.EpochContext(context.Background())
+}
+
+func (c *Account) EpochContext(ctx context.Context) (*nm.EpochInfo, error) {
 	payload, err := new(nm.GetCurrentEpochInfoParam).Encode()
 	if err != nil {
 		return nil, err
 	}
-	output, err := c.callNodeManager(payload, nil)
+	output, err := c.callNodeManager(ctx, payload, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -60,6 +65,10 @@ func (c *Account) Epoch() (*nm.EpochInfo, error) {
 }
 
 func (c *Account) Register(validator common.Address, amount *big.Int, desc string) (common.Hash, error) {
+	return c.RegisterContext(context.Background(), validator, amount, desc)
+}
+
+func (c *Account) RegisterContext(ctx context.Context, validator common.Address, amount *big.Int, desc string) (common.Hash, error) {
 	input := &nm.CreateValidatorParam{
 		ConsensusAddress: validator,
 		SignerAddress:    validator,
@@ -74,10 +83,14 @@ func (c *Account) Register(validator common.Address, amount *big.Int, desc strin
 		return common.EmptyHash, err
 	}
 
-	return c.sendNodeManagerTx(payload)
+	return c.sendNodeManagerTx(ctx, payload)
 }
 
 func (c *Account) Stake(validator common.Address, amount *big.Int) (common.Hash, error) {
+	return c.StakeContext(context.Background(), validator, amount)
+}
+
+func (c *Account) StakeContext(ctx context.Context, validator common.Address, amount *big.Int) (common.Hash, error) {
 	input := &nm.StakeParam{
 		ConsensusAddress: validator,
 		Amount:           amount,
@@ -86,13 +99,14 @@ func (c *Account) Stake(validator common.Address, amount *big.Int) (common.Hash,
 	if err != nil {
 		return common.EmptyHash, err
 	}
-	return c.sendNodeManagerTx(payload)
+	return c.sendNodeManagerTx(ctx, payload)
 }
 
-func (c *Account) sendNodeManagerTx(payload []byte) (common.Hash, error) {
-	return c.signAndSendTx(payload, nodeManagerAddr)
+func (c *Account) sendNodeManagerTx(ctx context.Context, payload []byte) (common.Hash, error) {
+	hash, _, err := c.signAndSendTx(ctx, payload, nodeManagerAddr)
+	return hash, err
 }
 
-func (c *Account) callNodeManager(payload []byte, blockNum *big.Int) ([]byte, error) {
-	return c.CallContract(c.Addr(), nodeManagerAddr, payload, blockNum)
+func (c *Account) callNodeManager(ctx context.Context, payload []byte, blockNum *big.Int) ([]byte, error) {
+	return c.CallContractContext(ctx, c.Addr(), nodeManagerAddr, payload, blockNum)
 }
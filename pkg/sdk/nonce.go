@@ -0,0 +1,161 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// NonceFetcher returns the nonce of account including the pending txs in pool
+type NonceFetcher func(ctx context.Context) (uint64, error)
+
+// NonceManager allocates nonces for concurrent senders of the same account.
+// nonces are pending after allocated, and should be committed once the tx is
+// accepted by node, or released if it's never broadcast. released nonces are
+// reused first, since the txs after them can't be packed until they are filled.
+type NonceManager struct {
+	mu       sync.Mutex
+	fetch    NonceFetcher
+	next     uint64
+	pending  map[uint64]struct{}
+	released []uint64 // sorted
+}
+
+func NewNonceManager(fetch NonceFetcher) *NonceManager {
+	return &NonceManager{
+		fetch:   fetch,
+		pending: make(map[uint64]struct{}),
+	}
+}
+
+// Next allocates the lowest released nonce, or the next one
+func (m *NonceManager) Next() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var nonce uint64
+	if len(m.released) > 0 {
+		nonce, m.released = m.released[0], m.released[1:]
+	} else {
+		nonce = m.next
+		m.next++
+	}
+	m.pending[nonce] = struct{}{}
+	return nonce
+}
+
+// Peek returns the nonce allocated by the next call of Next
+func (m *NonceManager) Peek() uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.released) > 0 {
+		return m.released[0]
+	}
+	return m.next
+}
+
+// Commit marks the nonce used by the tx accepted by node
+func (m *NonceManager) Commit(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.pending, nonce)
+}
+
+// Release returns the nonce of tx failed to broadcast. the allocated tail is
+// rolled back, and the nonce in the middle is kept as gap to be reused.
+func (m *NonceManager) Release(nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.pending[nonce]; !ok {
+		return
+	}
+	delete(m.pending, nonce)
+
+	m.released = append(m.released, nonce)
+	sort.Slice(m.released, func(i, j int) bool { return m.released[i] < m.released[j] })
+	for len(m.released) > 0 && m.released[len(m.released)-1] == m.next-1 {
+		m.released = m.released[:len(m.released)-1]
+		m.next--
+	}
+}
+
+// Gaps returns the released nonces which are not reused yet, the txs with
+// higher nonce are stuck in pool until the gaps are filled.
+func (m *NonceManager) Gaps() []uint64 {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]uint64{}, m.released...)
+}
+
+// Pending returns the number of nonces allocated but not committed or released
+func (m *NonceManager) Pending() int {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return len(m.pending)
+}
+
+// Resync fetches the pending nonce from node. gaps below it are filled by
+// others and dropped, and the next nonce never goes back while there are
+// nonces in use, which would be allocated twice.
+func (m *NonceManager) Resync(ctx context.Context) (uint64, error) {
+	nonce, err := m.fetch(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if len(m.pending) == 0 || nonce > m.next {
+		m.next = nonce
+	}
+	released := m.released[:0]
+	for _, n := range m.released {
+		if n >= nonce && n < m.next {
+			released = append(released, n)
+		}
+	}
+	m.released = released
+	return m.next, nil
+}
+
+// nonceErrors are returned by node if the nonce of tx is out of sync
+var nonceErrors = []string{
+	"nonce too low",
+	"already known",
+	"replacement transaction underpriced",
+	"replacement underpriced",
+}
+
+// IsNonceError returns true if the tx is rejected for used nonce, and the
+// nonce of account should be resynced from node.
+func IsNonceError(err error) bool {
+	if err == nil {
+		return false
+	}
+	msg := strings.ToLower(err.Error())
+	for _, s := range nonceErrors {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,122 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func fixedNonce(nonce *uint64) NonceFetcher {
+	return func(ctx context.Context) (uint64, error) {
+		return *nonce, nil
+	}
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestNonceManager
+func TestNonceManager(t *testing.T) {
+	remote := uint64(5)
+	m := NewNonceManager(fixedNonce(&remote))
+	next, err := m.Resync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(5), next)
+
+	n5, n6, n7 := m.Next(), m.Next(), m.Next()
+	assert.Equal(t, []uint64{5, 6, 7}, []uint64{n5, n6, n7})
+	assert.Equal(t, 3, m.Pending())
+
+	// tail is rolled back, and the middle one is kept as gap
+	m.Commit(n5)
+	m.Release(n7)
+	assert.Equal(t, uint64(7), m.Peek())
+	m.Release(n6)
+	assert.Equal(t, uint64(6), m.Peek())
+	assert.Empty(t, m.Gaps())
+
+	n6, n7 = m.Next(), m.Next()
+	m.Commit(n7)
+	m.Release(n6)
+	assert.Equal(t, []uint64{6}, m.Gaps())
+	assert.Equal(t, uint64(6), m.Next())
+	assert.Equal(t, uint64(8), m.Next())
+
+	// node is ahead, e.g: txs sent by others with the same account
+	remote = 20
+	next, err = m.Resync(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(20), next)
+	assert.Equal(t, uint64(20), m.Next())
+
+	// never go back while nonces are in use
+	remote = 10
+	next, _ = m.Resync(context.Background())
+	assert.Equal(t, uint64(21), next)
+}
+
+func TestNonceManagerConcurrent(t *testing.T) {
+	remote := uint64(0)
+	m := NewNonceManager(fixedNonce(&remote))
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		used = make(map[uint64]bool)
+	)
+	for i := 0; i < 100; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			nonce := m.Next()
+			if i%3 == 0 {
+				m.Release(nonce)
+				return
+			}
+			mu.Lock()
+			assert.False(t, used[nonce], "nonce %d allocated twice", nonce)
+			used[nonce] = true
+			mu.Unlock()
+			m.Commit(nonce)
+		}(i)
+	}
+	wg.Wait()
+
+	// released nonces are reused until no gap left
+	for len(m.Gaps()) > 0 {
+		nonce := m.Next()
+		assert.False(t, used[nonce])
+		used[nonce] = true
+		m.Commit(nonce)
+	}
+	assert.Equal(t, 0, m.Pending())
+	for n := uint64(0); n < m.Peek(); n++ {
+		assert.True(t, used[n], "nonce %d is missing", n)
+	}
+}
+
+func TestIsNonceError(t *testing.T) {
+	assert.True(t, IsNonceError(errors.New("nonce too low")))
+	assert.True(t, IsNonceError(errors.New("already known")))
+	assert.True(t, IsNonceError(errors.New("replacement transaction underpriced")))
+	assert.False(t, IsNonceError(errors.New("insufficient funds for gas * price + value")))
+	assert.False(t, IsNonceError(nil))
+}
@@ -0,0 +1,204 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/log"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// BlockPollInterval is the interval of polling the current block number
+var BlockPollInterval = 500 * time.Millisecond
+
+var (
+	ErrWaitTimeout = errors.New("wait timeout")
+	// ErrTxDropped is returned if the tx is neither packed nor in pool
+	ErrTxDropped = errors.New("transaction dropped")
+	// ErrTxReverted is returned with the receipt of failed tx
+	ErrTxReverted = errors.New("transaction reverted")
+)
+
+// DefaultTxWaitTimeout limits WaitTransaction of new accounts
+const DefaultTxWaitTimeout = 5 * time.Minute
+
+var (
+	// droppedChecks is the number of checks the tx not found before dropped,
+	// since the node may not index the tx right after it's broadcast.
+	droppedChecks = 3
+)
+
+// SetWaitTimeout sets the timeout of WaitTransaction, use WaitReceipt for
+// other limits. 0 means no limit other than ctx.
+func (c *Account) SetWaitTimeout(timeout time.Duration) {
+	c.waitTimeout = timeout
+}
+
+// SetConfirmations sets the confirmation depth of WaitTransaction, 0 by default
+func (c *Account) SetConfirmations(n uint64) {
+	c.confirmations = n
+}
+
+// Condition is checked by WaitUntil once for every new block, height is the
+// current block number.
+type Condition func(ctx context.Context, height uint64) (bool, error)
+
+// WaitBlocks waits until n new blocks produced, it returns the block number
+// reached. timeout 0 means no limit other than ctx.
+func (c *Account) WaitBlocks(ctx context.Context, n uint64, timeout time.Duration) (uint64, error) {
+	current, err := c.CurrentBlockNumberContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return c.WaitHeight(ctx, current+n, timeout)
+}
+
+// WaitHeight waits until the chain reaches height, it returns the block
+// number reached which may be greater than height.
+func (c *Account) WaitHeight(ctx context.Context, height uint64, timeout time.Duration) (uint64, error) {
+	var reached uint64
+	err := c.WaitUntil(ctx, func(ctx context.Context, current uint64) (bool, error) {
+		reached = current
+		return current >= height, nil
+	}, timeout)
+	if err != nil {
+		return reached, fmt.Errorf("wait height %d, current %d: %w", height, reached, err)
+	}
+	return reached, nil
+}
+
+// WaitUntil checks cond at the current block and every new block until it
+// holds or returns error. it fails with ErrWaitTimeout if timeout reached,
+// and with the ctx error if ctx is done.
+func (c *Account) WaitUntil(ctx context.Context, cond Condition, timeout time.Duration) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ticker := time.NewTicker(BlockPollInterval)
+	defer ticker.Stop()
+
+	var (
+		checked bool
+		last    uint64
+	)
+	for {
+		height, err := c.CurrentBlockNumberContext(ctx)
+		if err != nil && ctx.Err() == nil {
+			log.Warnf("failed to get current block number, err: %v", err)
+		}
+		if err == nil && (!checked || height > last) {
+			ok, err := cond(ctx, height)
+			if err != nil {
+				return err
+			}
+			if ok {
+				return nil
+			}
+			checked, last = true, height
+		}
+
+		select {
+		case <-ctx.Done():
+			if timeout > 0 && ctx.Err() == context.DeadlineExceeded {
+				return fmt.Errorf("%w after %v", ErrWaitTimeout, timeout)
+			}
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// TxsPacked is the condition which holds when all transactions are packed,
+// it fails if any of them is reverted.
+func (c *Account) TxsPacked(hashes ...common.Hash) Condition {
+	return func(ctx context.Context, height uint64) (bool, error) {
+		for _, hash := range hashes {
+			receipt, err := c.client.TransactionReceipt(ctx, hash)
+			if err == ethereum.NotFound {
+				return false, nil
+			}
+			if err != nil {
+				log.Warnf("failed to get receipt %s, err: %v", hash.Hex(), err)
+				return false, nil
+			}
+			if receipt.Status != types.ReceiptStatusSuccessful {
+				return false, fmt.Errorf("receipt failed %s", hash.Hex())
+			}
+		}
+		return true, nil
+	}
+}
+
+// WaitReceipt waits until the tx is packed and confirmed by confirmations
+// blocks on top of it, the receipt is fetched again on every new block in
+// case of reorg. it fails with ErrTxReverted along with the receipt if the tx
+// failed, ErrTxDropped if the tx is missing in both chain and pool, and
+// ErrWaitTimeout if timeout reached. timeout 0 means no limit other than ctx.
+func (c *Account) WaitReceipt(ctx context.Context, hash common.Hash, timeout time.Duration, confirmations uint64) (*Receipt, error) {
+	var (
+		receipt *types.Receipt
+		missing int
+	)
+	err := c.WaitUntil(ctx, func(ctx context.Context, height uint64) (bool, error) {
+		r, err := c.client.TransactionReceipt(ctx, hash)
+		if err == ethereum.NotFound {
+			receipt = nil
+			if _, _, err := c.client.TransactionByHash(ctx, hash); err == ethereum.NotFound {
+				if missing++; missing >= droppedChecks {
+					return false, ErrTxDropped
+				}
+			} else {
+				missing = 0
+			}
+			return false, nil
+		}
+		if err != nil {
+			log.Warnf("failed to get receipt %s, err: %v", hash.Hex(), err)
+			return false, nil
+		}
+
+		receipt, missing = r, 0
+		if receipt.Status != types.ReceiptStatusSuccessful {
+			return false, ErrTxReverted
+		}
+		return height >= receipt.BlockNumber.Uint64()+confirmations, nil
+	}, timeout)
+	if receipt == nil {
+		return nil, fmt.Errorf("wait transaction %s: %w", hash.Hex(), err)
+	}
+
+	res, rerr := c.newReceipt(ctx, receipt)
+	if rerr != nil {
+		log.Warnf("failed to get effective gas price of %s, err: %v", hash.Hex(), rerr)
+		res = &Receipt{Receipt: receipt}
+	}
+	if err != nil {
+		return res, fmt.Errorf("wait transaction %s: %w", hash.Hex(), err)
+	}
+	return res, nil
+}
@@ -0,0 +1,93 @@
+/*
+ * Copyright (C) 2021 The Zion Authors
+ * This file is part of The Zion library.
+ *
+ * The Zion is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The Zion is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE.  See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with The Zion.  If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package sdk
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/assert"
+)
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestWaitBlocks
+func TestWaitBlocks(t *testing.T) {
+	ctx := context.Background()
+	start, err := master.CurrentBlockNumber()
+	assert.NoError(t, err)
+
+	height, err := master.WaitBlocks(ctx, 2, time.Minute)
+	assert.NoError(t, err)
+	assert.True(t, height >= start+2)
+
+	_, err = master.WaitHeight(ctx, height+1000, time.Second)
+	assert.True(t, errors.Is(err, ErrWaitTimeout))
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestWaitTxsPacked
+func TestWaitTxsPacked(t *testing.T) {
+	to := common.HexToAddress("0x67CDE763bD045B14898d8B044F8afC8695ae8608")
+	tx, err := master.NewSignedTx(to, testEth1, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, master.SendTx(tx))
+	assert.NoError(t, master.WaitUntil(context.Background(), master.TxsPacked(tx.Hash()), time.Minute))
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestWaitReceipt
+func TestWaitReceipt(t *testing.T) {
+	ctx := context.Background()
+	to := common.HexToAddress("0x67CDE763bD045B14898d8B044F8afC8695ae8608")
+	tx, err := master.NewSignedTx(to, testEth1, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, master.SendTx(tx))
+
+	receipt, err := master.WaitReceipt(ctx, tx.Hash(), time.Minute, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, tx.Hash(), receipt.TxHash)
+	height, err := master.CurrentBlockNumber()
+	assert.NoError(t, err)
+	assert.True(t, height >= receipt.BlockNumber.Uint64()+2)
+
+	_, err = master.WaitReceipt(ctx, common.HexToHash("0x01"), time.Minute, 0)
+	assert.True(t, errors.Is(err, ErrTxDropped))
+}
+
+// go test -v github.com/dylenfu/zion-tool/pkg/sdk -run TestWaitTransaction
+func TestWaitTransaction(t *testing.T) {
+	privKey, _ := crypto.HexToECDSA(testMainNodeKey)
+	acc, err := CustomNewAccount(testChainID, testUrl, privKey)
+	assert.NoError(t, err)
+	assert.Equal(t, DefaultTxWaitTimeout, acc.waitTimeout)
+	acc.SetWaitTimeout(time.Minute)
+	acc.SetConfirmations(2)
+
+	to := common.HexToAddress("0x67CDE763bD045B14898d8B044F8afC8695ae8608")
+	tx, err := acc.NewSignedTx(to, testEth1, nil)
+	assert.NoError(t, err)
+	assert.NoError(t, acc.SendTx(tx))
+
+	receipt, err := acc.WaitTransaction(tx.Hash())
+	assert.NoError(t, err)
+	height, err := acc.CurrentBlockNumber()
+	assert.NoError(t, err)
+	assert.True(t, height >= receipt.BlockNumber.Uint64()+2)
+}
@@ -0,0 +1,430 @@
+package server
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+const (
+	// maxRuns is the number of finished runs kept for querying
+	maxRuns = 50
+	// logBuffer is the max number of log lines pending for collecting
+	logBuffer = 4096
+)
+
+type RunStatus string
+
+const (
+	RunRunning  RunStatus = "running"
+	RunFinished RunStatus = "finished"
+)
+
+var ErrBusy = errors.New("another run is in progress")
+
+// ScenarioLoader resolves scenario name into steps
+type ScenarioLoader func(name string) ([]*frame.Step, error)
+
+// RunRequest starts a run with one of Scenario, Steps or Methods, Params
+// overrides the params of steps by step name.
+type RunRequest struct {
+	Methods  []string                   `json:",omitempty"`
+	Scenario string                     `json:",omitempty"`
+	Steps    []*frame.Step              `json:",omitempty"`
+	Params   map[string]json.RawMessage `json:",omitempty"`
+}
+
+// Run is a run triggered remotely, its log lines are collected for streaming
+type Run struct {
+	ID      string
+	Status  RunStatus
+	Request *RunRequest
+	Start   time.Time
+	End     *time.Time       `json:",omitempty"`
+	Report  *frame.RunReport `json:",omitempty"`
+
+	mu      sync.Mutex
+	lines   []string
+	updated chan struct{}
+}
+
+func newRun(id string, req *RunRequest) *Run {
+	return &Run{
+		ID:      id,
+		Status:  RunRunning,
+		Request: req,
+		Start:   time.Now(),
+		updated: make(chan struct{}),
+	}
+}
+
+func (r *Run) append(line string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.lines = append(r.lines, line)
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+func (r *Run) finish(report *frame.RunReport) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	end := time.Now()
+	r.End = &end
+	r.Status = RunFinished
+	r.Report = report
+	close(r.updated)
+	r.updated = make(chan struct{})
+}
+
+// logs returns the lines after cursor, whether the run finished, and the
+// channel closed on the next update.
+func (r *Run) logs(cursor int) ([]string, bool, <-chan struct{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]string{}, r.lines[cursor:]...), r.Status == RunFinished, r.updated
+}
+
+func (r *Run) MarshalJSON() ([]byte, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	type run Run
+	return json.Marshal((*run)(r))
+}
+
+// Server exposes PaletteTool with REST api, only one run is allowed at the
+// same time since methods share the tool and run state.
+//
+//	GET  /methods          registered methods
+//	POST /runs             start a run, 409 if another one is in progress
+//	GET  /runs             runs in the order they started
+//	GET  /runs/{id}        run status and report
+//	GET  /runs/{id}/logs   server-sent events of run logs
+//
+// Requests must carry `Authorization: Bearer <token>` if token is set, and
+// the token is required when listening on non-loopback address.
+type Server struct {
+	tool     *frame.PaletteTool
+	scenario ScenarioLoader
+	token    string
+
+	mu      sync.Mutex
+	seq     int
+	runs    []*Run
+	current *Run
+}
+
+func New(tool *frame.PaletteTool, scenario ScenarioLoader) *Server {
+	return &Server{tool: tool, scenario: scenario}
+}
+
+// SetToken sets the bearer token required by every request
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/methods", s.handleMethods)
+	mux.HandleFunc("/runs", s.handleRuns)
+	mux.HandleFunc("/runs/", s.handleRun)
+	if s.token == "" {
+		return mux
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		expect := "Bearer " + s.token
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), []byte(expect)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, http.StatusUnauthorized, fmt.Errorf("invalid token"))
+			return
+		}
+		mux.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) ListenAndServe(addr string) error {
+	if s.token == "" && !isLoopback(addr) {
+		return fmt.Errorf("token required for listening on non-loopback address %s", addr)
+	}
+	log.Infof("control server listening on %s", addr)
+	return http.ListenAndServe(addr, s.Handler())
+}
+
+// isLoopback checks the host of addr, empty host listens on all interfaces
+func isLoopback(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// Start resolves steps of request and runs them in background
+func (s *Server) Start(req *RunRequest) (*Run, error) {
+	steps, err := s.steps(req)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.current != nil {
+		return nil, ErrBusy
+	}
+	s.seq++
+	run := newRun(fmt.Sprintf("%d", s.seq), req)
+	s.current = run
+	s.runs = append(s.runs, run)
+	if len(s.runs) > maxRuns {
+		s.runs = s.runs[len(s.runs)-maxRuns:]
+	}
+
+	go s.run(run, steps)
+	return run, nil
+}
+
+func (s *Server) run(run *Run, steps []*frame.Step) {
+	lines, cancel := log.Subscribe(logBuffer)
+	collected := make(chan struct{})
+	go func() {
+		defer close(collected)
+		for line := range lines {
+			run.append(line)
+		}
+	}()
+
+	s.tool.StartSteps(steps)
+	cancel()
+	<-collected
+	run.finish(s.tool.Report())
+
+	s.mu.Lock()
+	s.current = nil
+	s.mu.Unlock()
+}
+
+func (s *Server) steps(req *RunRequest) ([]*frame.Step, error) {
+	var (
+		steps []*frame.Step
+		err   error
+	)
+	switch {
+	case req.Scenario != "":
+		if s.scenario == nil {
+			return nil, fmt.Errorf("scenario not supported")
+		}
+		// only scenarios in workspace are allowed
+		if strings.ContainsAny(req.Scenario, `/\`) || strings.Contains(req.Scenario, "..") {
+			return nil, fmt.Errorf("invalid scenario name %s", req.Scenario)
+		}
+		if steps, err = s.scenario(req.Scenario); err != nil {
+			return nil, err
+		}
+	case len(req.Steps) > 0:
+		steps = copySteps(req.Steps)
+	default:
+		methods, err := s.tool.Select(req.Methods)
+		if err != nil {
+			return nil, err
+		}
+		steps = frame.NewSteps(methods)
+	}
+	if len(steps) == 0 {
+		return nil, fmt.Errorf("no method to run")
+	}
+
+	for name, params := range req.Params {
+		matched := false
+		for _, step := range steps {
+			if step.Name == name || (step.Name == "" && step.Method == name) {
+				step.Params = params
+				matched = true
+			}
+		}
+		if !matched {
+			return nil, fmt.Errorf("params of %s matches no step", name)
+		}
+	}
+	return steps, nil
+}
+
+// copySteps copies the steps of request, so that the run never touches the
+// request which is serialized concurrently by the run queries.
+func copySteps(steps []*frame.Step) []*frame.Step {
+	list := make([]*frame.Step, 0, len(steps))
+	for _, step := range steps {
+		cp := *step
+		if step.Params != nil {
+			cp.Params = append(json.RawMessage{}, step.Params...)
+		}
+		if step.DependsOn != nil {
+			cp.DependsOn = append([]string{}, step.DependsOn...)
+		}
+		list = append(list, &cp)
+	}
+	return list
+}
+
+// Run returns the run by id
+func (s *Server) Run(id string) *Run {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, run := range s.runs {
+		if run.ID == id {
+			return run
+		}
+	}
+	return nil
+}
+
+type methodView struct {
+	Name        string
+	Description string          `json:",omitempty"`
+	Tags        []string        `json:",omitempty"`
+	DependsOn   []string        `json:",omitempty"`
+	CaseFile    string          `json:",omitempty"`
+	Params      json.RawMessage `json:",omitempty"`
+}
+
+func (s *Server) handleMethods(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	list := make([]*methodView, 0)
+	for _, info := range s.tool.Methods() {
+		view := &methodView{
+			Name:        info.Name,
+			Description: info.Description,
+			Tags:        info.Tags,
+			DependsOn:   info.DependsOn,
+			CaseFile:    info.CaseFile,
+		}
+		if info.Params != nil {
+			view.Params, _ = info.SampleParams()
+		}
+		list = append(list, view)
+	}
+	writeJSON(w, http.StatusOK, list)
+}
+
+func (s *Server) handleRuns(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.mu.Lock()
+		runs := append([]*Run{}, s.runs...)
+		s.mu.Unlock()
+		writeJSON(w, http.StatusOK, runs)
+
+	case http.MethodPost:
+		req := new(RunRequest)
+		if err := json.NewDecoder(r.Body).Decode(req); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid request, err: %v", err))
+			return
+		}
+		run, err := s.Start(req)
+		if err == ErrBusy {
+			writeError(w, http.StatusConflict, err)
+			return
+		}
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		writeJSON(w, http.StatusAccepted, run)
+
+	default:
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+	}
+}
+
+func (s *Server) handleRun(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		writeError(w, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+
+	parts := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, "/runs/"), "/"), "/")
+	run := s.Run(parts[0])
+	if run == nil {
+		writeError(w, http.StatusNotFound, fmt.Errorf("run %s not found", parts[0]))
+		return
+	}
+	switch {
+	case len(parts) == 1:
+		writeJSON(w, http.StatusOK, run)
+	case len(parts) == 2 && parts[1] == "logs":
+		s.streamLogs(w, r, run)
+	default:
+		writeError(w, http.StatusNotFound, fmt.Errorf("path %s not found", r.URL.Path))
+	}
+}
+
+// streamLogs sends the collected log lines of run as server-sent events, and
+// then the new ones until the run finished with an `end` event.
+func (s *Server) streamLogs(w http.ResponseWriter, r *http.Request, run *Run) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("streaming not supported"))
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+
+	cursor := 0
+	for {
+		lines, finished, updated := run.logs(cursor)
+		for _, line := range lines {
+			writeEvent(w, line)
+		}
+		cursor += len(lines)
+		if finished {
+			fmt.Fprintf(w, "event: end\ndata: %s\n\n", run.ID)
+			flusher.Flush()
+			return
+		}
+		flusher.Flush()
+
+		select {
+		case <-r.Context().Done():
+			return
+		case <-updated:
+		}
+	}
+}
+
+// writeEvent writes the log entry as one event, every line of a multi-line
+// entry gets its own data field so that the event framing keeps intact.
+func writeEvent(w http.ResponseWriter, entry string) {
+	for _, line := range strings.Split(strings.TrimSuffix(entry, "\n"), "\n") {
+		fmt.Fprintf(w, "data: %s\n", line)
+	}
+	fmt.Fprint(w, "\n")
+}
+
+func writeJSON(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Errorf("failed to write response, err: %v", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, code int, err error) {
+	writeJSON(w, code, map[string]string{"error": err.Error()})
+}
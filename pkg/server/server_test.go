@@ -0,0 +1,250 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/dylenfu/zion-tool/pkg/frame"
+	"github.com/dylenfu/zion-tool/pkg/log"
+)
+
+func post(t *testing.T, url string, req *RunRequest) (*http.Response, *Run) {
+	enc, _ := json.Marshal(req)
+	resp, err := http.Post(url+"/runs", "application/json", bytes.NewReader(enc))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	run := new(Run)
+	json.NewDecoder(resp.Body).Decode(run)
+	return resp, run
+}
+
+func TestServer(t *testing.T) {
+	log.InitLog(log.InfoLog)
+
+	release := make(chan struct{})
+	tool := frame.NewPaletteTool()
+	tool.SetInterval(0)
+	tool.RegHandler("transfer", func(ctx context.Context) *frame.Result {
+		<-release
+		var param struct{ Amount int }
+		if err := json.Unmarshal(frame.StepParams(ctx), &param); err != nil {
+			return frame.Fail(err)
+		}
+		log.Infof("transfer amount %d", param.Amount)
+		return frame.Succeed()
+	}, frame.WithTags("smoke"))
+
+	srv := httptest.NewServer(New(tool, nil).Handler())
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/methods")
+	if err != nil {
+		t.Fatal(err)
+	}
+	methods := make([]*methodView, 0)
+	json.NewDecoder(resp.Body).Decode(&methods)
+	resp.Body.Close()
+	if len(methods) != 1 || methods[0].Name != "transfer" {
+		t.Fatalf("unexpected methods %+v", methods)
+	}
+
+	if resp, _ := post(t, srv.URL, &RunRequest{Methods: []string{"unknown"}}); resp.StatusCode != http.StatusBadRequest {
+		t.Fatalf("expect bad request, got %d", resp.StatusCode)
+	}
+	resp, run := post(t, srv.URL, &RunRequest{
+		Methods: []string{"tag:smoke"},
+		Params:  map[string]json.RawMessage{"transfer": json.RawMessage(`{"Amount":7}`)},
+	})
+	if resp.StatusCode != http.StatusAccepted || run.ID == "" {
+		t.Fatalf("expect run accepted, got %d", resp.StatusCode)
+	}
+	if resp, _ := post(t, srv.URL, &RunRequest{Methods: []string{"transfer"}}); resp.StatusCode != http.StatusConflict {
+		t.Fatalf("expect conflict, got %d", resp.StatusCode)
+	}
+
+	stream, err := http.Get(srv.URL + "/runs/" + run.ID + "/logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	close(release)
+
+	var (
+		found  bool
+		ended  bool
+		reader = bufio.NewScanner(stream.Body)
+	)
+	for reader.Scan() {
+		line := reader.Text()
+		if strings.HasPrefix(line, "data: ") && strings.HasSuffix(line, "transfer amount 7") {
+			found = true
+		}
+		if line == "event: end" {
+			ended = true
+		}
+	}
+	if !found || !ended {
+		t.Fatalf("expect log line and end event, found %v, ended %v", found, ended)
+	}
+
+	resp, err = http.Get(srv.URL + "/runs/" + run.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	status := new(Run)
+	json.NewDecoder(resp.Body).Decode(status)
+	if status.Status != RunFinished || status.Report == nil || status.Report.Success != 1 {
+		t.Fatalf("unexpected run status %+v", status)
+	}
+	if resp, _ := http.Get(srv.URL + "/runs/100"); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("expect not found, got %d", resp.StatusCode)
+	}
+}
+
+func TestServerRunSteps(t *testing.T) {
+	release := make(chan struct{})
+	tool := frame.NewPaletteTool()
+	tool.SetInterval(0)
+	tool.RegHandler("transfer", func(ctx context.Context) *frame.Result {
+		<-release
+		return frame.Succeed()
+	})
+
+	srv := httptest.NewServer(New(tool, nil).Handler())
+	defer srv.Close()
+
+	// steps without name are queried while running
+	req := &RunRequest{Steps: []*frame.Step{{Method: "transfer"}, {Method: "transfer", DependsOn: []string{"transfer"}}}}
+	resp, run := post(t, srv.URL, req)
+	if resp.StatusCode != http.StatusAccepted {
+		t.Fatalf("expect run accepted, got %d", resp.StatusCode)
+	}
+	status := new(Run)
+	for i := 0; i < 10; i++ {
+		resp, err := http.Get(srv.URL + "/runs/" + run.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(resp.Body).Decode(status)
+		resp.Body.Close()
+	}
+	if status.Request.Steps[0].Name != "" {
+		t.Fatalf("expect request steps untouched, got %+v", status.Request.Steps[0])
+	}
+	close(release)
+
+	for status.Status != RunFinished {
+		time.Sleep(10 * time.Millisecond)
+		resp, err := http.Get(srv.URL + "/runs/" + run.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		json.NewDecoder(resp.Body).Decode(status)
+		resp.Body.Close()
+	}
+	if status.Report.Success != 2 || status.Report.Methods[0].Name != "transfer" {
+		t.Fatalf("unexpected report %+v", status.Report)
+	}
+}
+
+func TestWriteEvent(t *testing.T) {
+	var testdata = []struct {
+		entry  string
+		expect string
+	}{
+		{"transfer amount 7", "data: transfer amount 7\n\n"},
+		{"failed\nstack line\n", "data: failed\ndata: stack line\n\n"},
+		{"a\n\nb", "data: a\ndata: \ndata: b\n\n"},
+	}
+	for _, v := range testdata {
+		w := httptest.NewRecorder()
+		writeEvent(w, v.entry)
+		if got := w.Body.String(); got != v.expect {
+			t.Errorf("entry %q expect %q, got %q", v.entry, v.expect, got)
+		}
+	}
+}
+
+func TestServerToken(t *testing.T) {
+	s := New(frame.NewPaletteTool(), nil)
+	s.SetToken("secret")
+	srv := httptest.NewServer(s.Handler())
+	defer srv.Close()
+
+	var testdata = []struct {
+		header string
+		expect int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"secret", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+	for _, v := range testdata {
+		req, _ := http.NewRequest(http.MethodGet, srv.URL+"/methods", nil)
+		if v.header != "" {
+			req.Header.Set("Authorization", v.header)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != v.expect {
+			t.Errorf("header %q expect %d, got %d", v.header, v.expect, resp.StatusCode)
+		}
+	}
+
+	if err := New(frame.NewPaletteTool(), nil).ListenAndServe(":0"); err == nil {
+		t.Fatal("expect token required on all interfaces")
+	}
+}
+
+func TestIsLoopback(t *testing.T) {
+	var testdata = []struct {
+		addr   string
+		expect bool
+	}{
+		{"127.0.0.1:8080", true},
+		{"localhost:8080", true},
+		{"[::1]:8080", true},
+		{":8080", false},
+		{"0.0.0.0:8080", false},
+		{"10.0.0.1:8080", false},
+		{"127.0.0.1", false},
+	}
+	for _, v := range testdata {
+		if got := isLoopback(v.addr); got != v.expect {
+			t.Errorf("addr %s expect %v, got %v", v.addr, v.expect, got)
+		}
+	}
+}
+
+func TestServerScenarioName(t *testing.T) {
+	loaded := ""
+	s := New(frame.NewPaletteTool(), func(name string) ([]*frame.Step, error) {
+		loaded = name
+		return []*frame.Step{{Method: "transfer"}}, nil
+	})
+	for _, name := range []string{"../config.json", "/etc/passwd", `..\config.json`, "a/b.json", ".."} {
+		if _, err := s.steps(&RunRequest{Scenario: name}); err == nil {
+			t.Errorf("expect scenario %s rejected", name)
+		}
+	}
+	if loaded != "" {
+		t.Fatalf("expect no scenario loaded, got %s", loaded)
+	}
+	if _, err := s.steps(&RunRequest{Scenario: "smoke.json"}); err != nil || loaded != "smoke.json" {
+		t.Fatalf("expect scenario loaded, got %s, %v", loaded, err)
+	}
+}